@@ -4,12 +4,13 @@ import (
 	"fmt"
 
 	"github.com/intersesh/crypto/blockcipher"
+	"github.com/intersesh/crypto/gf256"
 	"github.com/intersesh/crypto/matrix"
 )
 
 const (
 	// numColumns is always set to 4 for AES,
-	// although Rijndael supports a variable number of columns.
+	// although Rijndael supports a variable number of columns (see Rijndael).
 	// See 'Nb' parameter in FIPS-197 Section 2.2.
 	numColumns = 4
 )
@@ -18,24 +19,103 @@ const (
 // Depending on key size, will perform a different number of rounds during
 // encryption and decryption.
 type Cipher struct {
-	schedule  []Word
+	schedule  KeySchedule
 	numRounds int
+	keySize   int
+
+	// inverseSchedule is only populated when the cipher uses the
+	// Equivalent Inverse Cipher for decryption.
+	inverseSchedule   []Word
+	equivalentInverse bool
+
+	tracers []Tracer
+	faults  []FaultInjector
 }
 
-func NewCipher(key Key) Cipher {
+// Option configures optional behaviour of a Cipher.
+type Option func(*Cipher)
+
+// WithEquivalentInverse makes Decrypt use the Equivalent Inverse Cipher,
+// which applies the inverse transformations in the same order as Encrypt
+// at the cost of a modified key schedule.
+// See FIPS-197 Section 5.3.5.
+func WithEquivalentInverse() Option {
+	return func(c *Cipher) {
+		c.equivalentInverse = true
+	}
+}
+
+// WithRounds overrides the number of rounds, which is otherwise determined by
+// the key size. The key schedule is extended or shortened to match, and the
+// last round still omits column mixing.
+// Reduced-round variants are not secure; they exist for studying attacks such
+// as the Square attack, which breaks 4 rounds.
+// Panics if rounds is less than 1.
+func WithRounds(rounds int) Option {
+	if rounds < 1 {
+		panic(fmt.Sprintf("WithRounds: must use at least 1 round; received %d", rounds))
+	}
+
+	return func(c *Cipher) {
+		c.numRounds = rounds
+	}
+}
+
+// NewCipher returns a cipher for a key of any of the three AES key sizes.
+func NewCipher(key Key, opts ...Option) Cipher {
 	// In our implementation, Word is a 32-bit uint (which contains 4 bytes),
 	// which means that we can just take the length of the key to figure out
 	// how many words there are.
 	wordsInKey := len(key)
 
-	// How many rounds we do is always dependent on how large the key is.
+	// How many rounds we do is always dependent on how large the key is,
+	// unless overridden with WithRounds.
 	// Check 'Nr' parameter in FIPS-197 Section 2.2.
-	numRounds := 6 + wordsInKey
+	c := Cipher{
+		numRounds: 6 + wordsInKey,
+		keySize:   4 * wordsInKey,
+	}
+
+	for _, opt := range opts {
+		opt(&c)
+	}
 
-	return Cipher{
-		schedule:  expandKey(key, numRounds, wordsInKey, numColumns),
-		numRounds: numRounds,
+	c.schedule = expandKey(key, c.numRounds, wordsInKey, numColumns)
+	if c.equivalentInverse {
+		c.inverseSchedule = expandKeyInverse(c.schedule, c.numRounds, numColumns)
 	}
+
+	return c
+}
+
+// NewAES128 returns a cipher for a 128-bit key, which uses 10 rounds.
+func NewAES128(key [16]byte, opts ...Option) Cipher {
+	return NewCipher(NewKey(key[:]), opts...)
+}
+
+// NewAES192 returns a cipher for a 192-bit key, which uses 12 rounds.
+func NewAES192(key [24]byte, opts ...Option) Cipher {
+	return NewCipher(NewKey(key[:]), opts...)
+}
+
+// NewAES256 returns a cipher for a 256-bit key, which uses 14 rounds.
+func NewAES256(key [32]byte, opts ...Option) Cipher {
+	return NewCipher(NewKey(key[:]), opts...)
+}
+
+// KeySize returns the size of the cipher's key in bytes.
+func (c Cipher) KeySize() int {
+	return c.keySize
+}
+
+// Rounds returns the number of rounds the cipher performs, i.e. Nr.
+func (c Cipher) Rounds() int {
+	return c.numRounds
+}
+
+// KeySchedule returns a copy of the cipher's key schedule.
+func (c Cipher) KeySchedule() KeySchedule {
+	return append(KeySchedule{}, c.schedule...)
 }
 
 // Word is an array of 4 bytes represented as a single uint32.
@@ -71,23 +151,35 @@ func Words(bytes []byte) []Word {
 // See FIPS-197 Section 5.1.
 func (c Cipher) Encrypt(block blockcipher.Block) blockcipher.Block {
 	state := parse(block)
+	c.step(0, StepInput, state)
 
 	// The zeroth round only consists of adding the round key.
+	c.traceRoundKey(0, StepKeySchedule, c.schedule, 0)
 	state = addRoundKey(state, c.schedule, 0)
 
 	// The intermediate rounds consist of all four steps:
 	// byte substitution, row shifting, column mixing, and adding the round key.
 	for round := 1; round < c.numRounds; round++ {
+		c.step(round, StepStart, state)
 		state = subBytes(state)
+		c.step(round, StepSubBytes, state)
 		state = shiftRows(state)
+		c.step(round, StepShiftRows, state)
 		state = mixColumns(state, mixColumnPolynomials)
+		c.step(round, StepMixColumns, state)
+		c.traceRoundKey(round, StepKeySchedule, c.schedule, round)
 		state = addRoundKey(state, c.schedule, round)
 	}
 
 	// The last round excludes column mixing.
+	c.step(c.numRounds, StepStart, state)
 	state = subBytes(state)
+	c.step(c.numRounds, StepSubBytes, state)
 	state = shiftRows(state)
+	c.step(c.numRounds, StepShiftRows, state)
+	c.traceRoundKey(c.numRounds, StepKeySchedule, c.schedule, c.numRounds)
 	state = addRoundKey(state, c.schedule, c.numRounds)
+	c.step(c.numRounds, StepOutput, state)
 
 	return matrixBlock(state)
 }
@@ -96,20 +188,105 @@ func (c Cipher) Encrypt(block blockcipher.Block) blockcipher.Block {
 // It's effectively the inverse of the Encrypt function;
 // the steps are applied in reverse order.
 // See FIPS-197 Section 5.3.
+//
+// If the cipher was created with WithEquivalentInverse,
+// the Equivalent Inverse Cipher is used instead.
+//
+// Tracers see the rounds numbered in the order they are performed,
+// i.e. the key for round i is the one Encrypt uses in round Nr - i.
 func (c Cipher) Decrypt(block blockcipher.Block) blockcipher.Block {
+	if c.equivalentInverse {
+		return c.decryptEquivalent(block)
+	}
+
 	state := parse(block)
+	c.step(0, StepInverseInput, state)
 
+	c.traceRoundKey(0, StepInverseKeySchedule, c.schedule, c.numRounds)
 	state = addRoundKey(state, c.schedule, c.numRounds)
 
 	for round := c.numRounds - 1; round >= 1; round-- {
+		step := c.numRounds - round
+		c.step(step, StepInverseStart, state)
 		state = shiftRowsInverse(state)
+		c.step(step, StepInverseShiftRows, state)
 		state = subBytesInverse(state)
+		c.step(step, StepInverseSubBytes, state)
+		c.traceRoundKey(step, StepInverseKeySchedule, c.schedule, round)
 		state = addRoundKey(state, c.schedule, round)
+		c.step(step, StepInverseAddRoundKey, state)
 		state = mixColumns(state, mixColumnPolynomialsInverse)
 	}
+
+	c.step(c.numRounds, StepInverseStart, state)
 	state = shiftRowsInverse(state)
+	c.step(c.numRounds, StepInverseShiftRows, state)
 	state = subBytesInverse(state)
+	c.step(c.numRounds, StepInverseSubBytes, state)
+	c.traceRoundKey(c.numRounds, StepInverseKeySchedule, c.schedule, 0)
 	state = addRoundKey(state, c.schedule, 0)
+	c.step(c.numRounds, StepInverseOutput, state)
+
+	return matrixBlock(state)
+}
+
+// EncryptBlocks encrypts every 16-byte block of src into dst.
+// dst must be at least as long as src, and may be src itself to encrypt in place.
+// Panics if src is not a multiple of the block size or the buffers partially overlap.
+func (c Cipher) EncryptBlocks(dst, src []byte) {
+	c.cryptBlocks(c.Encrypt, dst, src)
+}
+
+// DecryptBlocks is the inverse of EncryptBlocks.
+func (c Cipher) DecryptBlocks(dst, src []byte) {
+	c.cryptBlocks(c.Decrypt, dst, src)
+}
+
+// cryptBlocks reads each block before it writes it back,
+// which is what makes exactly overlapping buffers safe.
+func (c Cipher) cryptBlocks(crypt func(blockcipher.Block) blockcipher.Block, dst, src []byte) {
+	blockcipher.CheckBlocks(dst, src)
+
+	for i := 0; i < len(src); i += 16 {
+		block := crypt(blockcipher.NewBlock(src[i : i+16]))
+		copy(dst[i:], block[:])
+	}
+}
+
+// decryptEquivalent is an implementation of the EqInvCipher function.
+// InvSubBytes and InvShiftRows commute, and InvMixColumns is linear,
+// so the steps can be applied in the same order as in Encrypt,
+// as long as the round keys have been transformed by InvMixColumns too.
+// See FIPS-197 Section 5.3.5.
+func (c Cipher) decryptEquivalent(block blockcipher.Block) blockcipher.Block {
+	state := parse(block)
+	c.step(0, StepInverseInput, state)
+
+	c.traceRoundKey(0, StepInverseKeySchedule, c.inverseSchedule, c.numRounds)
+	state = addRoundKey(state, c.inverseSchedule, c.numRounds)
+
+	for round := c.numRounds - 1; round >= 1; round-- {
+		step := c.numRounds - round
+		c.step(step, StepInverseStart, state)
+		state = subBytesInverse(state)
+		c.step(step, StepInverseSubBytes, state)
+		state = shiftRowsInverse(state)
+		c.step(step, StepInverseShiftRows, state)
+		state = mixColumns(state, mixColumnPolynomialsInverse)
+		c.step(step, StepInverseMixColumns, state)
+		c.traceRoundKey(step, StepInverseKeySchedule, c.inverseSchedule, round)
+		state = addRoundKey(state, c.inverseSchedule, round)
+	}
+
+	c.step(c.numRounds, StepInverseStart, state)
+	state = subBytesInverse(state)
+	c.step(c.numRounds, StepInverseSubBytes, state)
+	state = shiftRowsInverse(state)
+	c.step(c.numRounds, StepInverseShiftRows, state)
+	c.traceRoundKey(c.numRounds, StepInverseKeySchedule, c.inverseSchedule, 0)
+	state = addRoundKey(state, c.inverseSchedule, 0)
+	c.step(c.numRounds, StepInverseOutput, state)
+
 	return matrixBlock(state)
 }
 
@@ -118,12 +295,18 @@ func (c Cipher) Decrypt(block blockcipher.Block) blockcipher.Block {
 // in a column-first fashion
 // See FIPS-197 Section 3.4.
 func parse(block blockcipher.Block) matrix.Matrix {
-	return matrix.NewMatrix(block[:], 4).Transpose()
+	return parseBytes(block[:])
+}
+
+// parseBytes is like parse, but accepts blocks of any multiple of 4 bytes,
+// which results in a state with 4 rows and len(bytes)/4 columns.
+func parseBytes(bytes []byte) matrix.Matrix {
+	return matrix.NewMatrix(bytes, 4).Transpose()
 }
 
 func addRoundKey(state matrix.Matrix, schedule []Word, round int) matrix.Matrix {
-	numColumns := 4
-	out := matrix.EmptyMatrix(4, 4)
+	numColumns := len(state[0])
+	out := matrix.EmptyMatrix(numColumns, 4)
 
 	for i := 0; i < numColumns; i++ {
 		stateColumn := matrix.ColumnVector(state, i)
@@ -136,7 +319,7 @@ func addRoundKey(state matrix.Matrix, schedule []Word, round int) matrix.Matrix
 }
 
 func subBytes(state matrix.Matrix) matrix.Matrix {
-	out := matrix.EmptyMatrix(4, 4)
+	out := matrix.EmptyMatrix(len(state[0]), 4)
 
 	for row := range state {
 		for col := range state[row] {
@@ -148,7 +331,7 @@ func subBytes(state matrix.Matrix) matrix.Matrix {
 }
 
 func subBytesInverse(state matrix.Matrix) matrix.Matrix {
-	out := matrix.EmptyMatrix(4, 4)
+	out := matrix.EmptyMatrix(len(state[0]), 4)
 
 	for row := range state {
 		for col := range state[row] {
@@ -159,25 +342,43 @@ func subBytesInverse(state matrix.Matrix) matrix.Matrix {
 }
 
 func shiftRows(state matrix.Matrix) matrix.Matrix {
-	out := matrix.EmptyMatrix(4, 4)
+	offsets := shiftOffsets(len(state[0]))
+	out := matrix.EmptyMatrix(len(state[0]), 4)
 	for i := 0; i < 4; i++ {
-		out[i] = append(append(matrix.Vector{}, state[i][i:]...), state[i][:i]...)
+		pivot := offsets[i]
+		out[i] = append(append(matrix.Vector{}, state[i][pivot:]...), state[i][:pivot]...)
 	}
 
 	return out
 }
 
 func shiftRowsInverse(state matrix.Matrix) matrix.Matrix {
-	out := matrix.EmptyMatrix(4, 4)
+	offsets := shiftOffsets(len(state[0]))
+	out := matrix.EmptyMatrix(len(state[0]), 4)
 	for i := 0; i < 4; i++ {
-		pivot := 4 - i
+		pivot := len(state[i]) - offsets[i]
 		out[i] = append(append(matrix.Vector{}, state[i][pivot:]...), state[i][:pivot]...)
 	}
 	return out
 }
 
+// shiftOffsets returns how many positions each row of the state is rotated
+// by during row shifting. For AES, row i is always rotated by i positions,
+// but Rijndael uses larger offsets for wider blocks.
+// See 'The Design of Rijndael' Section 3.4.2.
+func shiftOffsets(numColumns int) [4]int {
+	switch numColumns {
+	case 7:
+		return [4]int{0, 1, 2, 4}
+	case 8:
+		return [4]int{0, 1, 3, 4}
+	default:
+		return [4]int{0, 1, 2, 3}
+	}
+}
+
 func mixColumns(state, polynomials matrix.Matrix) matrix.Matrix {
-	out := matrix.EmptyMatrix(4, 4)
+	out := matrix.EmptyMatrix(len(state[0]), 4)
 	for row := 0; row < len(state); row++ {
 		for col := 0; col < len(state[row]); col++ {
 			out[row][col] = DotProduct(matrix.RowVector(polynomials, row), matrix.ColumnVector(state, col))
@@ -209,11 +410,16 @@ func SubstituteWord(w Word) Word {
 }
 
 func matrixBlock(m matrix.Matrix) blockcipher.Block {
-	var out blockcipher.Block
-	for row := range m {
-		for col := range m[row] {
-			index := (row * 4) + col
-			out[index] = m[col][row]
+	return blockcipher.Block(matrixBytes(m))
+}
+
+// matrixBytes is the inverse of parseBytes;
+// the state is read back out column by column.
+func matrixBytes(m matrix.Matrix) []byte {
+	out := make([]byte, 0, len(m)*len(m[0]))
+	for col := range m[0] {
+		for row := range m {
+			out = append(out, m[row][col])
 		}
 	}
 
@@ -226,15 +432,11 @@ func RotateWord(w Word) Word {
 	return w<<8 | w>>24
 }
 
-// Rcon returns the round constant, which is a 4-bit polynomial represented
-// as a power of two raised by the round number, mod poly.
+// Rcon returns the round constant xⁱ in GF(2⁸), where i is the given round.
+// See FIPS-197 Section 5.2.
 //
 // The result is shifted three bytes to the left, since these constants are
-// always of the form x³.
+// always of the form [xⁱ, {00}, {00}, {00}].
 func Rcon(round int) Word {
-	return Word(Mod(Exp2(round), poly)) << 24
+	return Word(gf256.AES.Pow(0x02, round)) << 24
 }
-
-// poly is the irreducible polynomial for GF(2⁸),
-// chosen for the AES128 implementation.
-const poly = 1<<8 | 1<<4 | 1<<3 | 1<<1 | 1<<0 // x⁸ + x⁴ + x³ + x + 1
@@ -1,6 +1,7 @@
 package aes
 
 import (
+	"bytes"
 	"testing"
 
 	"github.com/intersesh/crypto/blockcipher"
@@ -18,6 +19,23 @@ func TestAES(t *testing.T) {
 	assert.Equal(t, blockcipher.Block{0x32, 0x43, 0xf6, 0xa8, 0x88, 0x5a, 0x30, 0x8d, 0x31, 0x31, 0x98, 0xa2, 0xe0, 0x37, 0x07, 0x34}, result)
 }
 
+// TestEquivalentInverseCipher checks that the Equivalent Inverse Cipher
+// decrypts to the same plaintext as the regular InvCipher.
+func TestEquivalentInverseCipher(t *testing.T) {
+	for _, size := range []int{16, 24, 32} {
+		for i := 0; i < 16; i++ {
+			key := NewKey(blockcipher.RandomBytes(size))
+			block := blockcipher.NewBlock(blockcipher.RandomBytes(16))
+
+			c := NewCipher(key)
+			eq := NewCipher(key, WithEquivalentInverse())
+
+			assert.Equal(t, c.Decrypt(block), eq.Decrypt(block), "key size %d", size)
+			assert.Equal(t, block, eq.Decrypt(c.Encrypt(block)), "key size %d", size)
+		}
+	}
+}
+
 // TestKeyExpansion128 values taken from FIPS-197 Appendix A.1.
 func TestKeyExpansion128(t *testing.T) {
 	key := Key(Words(
@@ -77,15 +95,194 @@ func TestKeyExpansion128(t *testing.T) {
 	}
 }
 
+// TestKeySchedule checks the round key accessors against FIPS-197 Appendix A.1,
+// and that the fixed-size constructors pick the right number of rounds.
+func TestKeySchedule(t *testing.T) {
+	c := NewAES128([16]byte{0x2b, 0x7e, 0x15, 0x16, 0x28, 0xae, 0xd2, 0xa6, 0xab, 0xf7, 0x15, 0x88, 0x09, 0xcf, 0x4f, 0x3c})
+	schedule := c.KeySchedule()
+
+	assert.Equal(t, 10, schedule.Rounds())
+	assert.Equal(t, [4]Word{0x2b7e1516, 0x28aed2a6, 0xabf71588, 0x09cf4f3c}, schedule.RoundKey(0))
+	assert.Equal(t, [4]Word{0xd014f9a8, 0xc9ee2589, 0xe13f0cc8, 0xb6630ca6}, schedule.RoundKey(10))
+	assert.Panics(t, func() { schedule.RoundKey(11) })
+
+	// The schedule is a copy, so changing it must not affect the cipher.
+	schedule[0] = 0
+	assert.Equal(t, Word(0x2b7e1516), c.KeySchedule()[0])
+
+	for _, tc := range []struct {
+		c               Cipher
+		keySize, rounds int
+	}{
+		{NewAES128([16]byte{}), 16, 10},
+		{NewAES192([24]byte{}), 24, 12},
+		{NewAES256([32]byte{}), 32, 14},
+	} {
+		assert.Equal(t, tc.keySize, tc.c.KeySize())
+		assert.Equal(t, tc.rounds, tc.c.Rounds())
+		assert.Equal(t, NewKeySchedule(make(Key, tc.keySize/4)), tc.c.KeySchedule())
+	}
+}
+
+// TestRecoverKey checks that every window of Nk words of the key schedule
+// leads back to the key, for random keys of each size.
+func TestRecoverKey(t *testing.T) {
+	for _, size := range []int{16, 24, 32} {
+		for i := 0; i < 8; i++ {
+			key := NewKey(blockcipher.RandomBytes(size))
+			schedule := NewKeySchedule(key)
+
+			for start := 0; start+len(key) <= len(schedule); start++ {
+				assert.Equal(t, key, RecoverKey(schedule[start:start+len(key)], start), "key size %d, start %d", size, start)
+			}
+		}
+	}
+
+	assert.Panics(t, func() { RecoverKey(make([]Word, 5), 0) })
+	assert.Panics(t, func() { RecoverKey(make([]Word, 4), 41) })
+}
+
+func TestWithRounds(t *testing.T) {
+	key := NewKey(blockcipher.RandomBytes(16))
+	block := blockcipher.NewBlock(blockcipher.RandomBytes(16))
+
+	assert.Equal(t, NewCipher(key).Encrypt(block), NewCipher(key, WithRounds(10)).Encrypt(block))
+
+	for _, rounds := range []int{1, 4, 5, 16} {
+		c := NewCipher(key, WithRounds(rounds))
+		eq := NewCipher(key, WithRounds(rounds), WithEquivalentInverse())
+
+		assert.Equal(t, rounds, c.Rounds())
+		assert.Equal(t, rounds, c.KeySchedule().Rounds())
+		assert.Equal(t, block, c.Decrypt(c.Encrypt(block)), "%d rounds", rounds)
+		assert.Equal(t, block, eq.Decrypt(c.Encrypt(block)), "%d rounds", rounds)
+	}
+
+	assert.Panics(t, func() { WithRounds(0) })
+}
+
 func TestModes(t *testing.T) {
 	key := NewKey([]byte("ABSENTMINDEDNESS"))
 	c := NewCipher(key)
 	for _, m := range []blockcipher.Mode{
 		blockcipher.NewECBMode(c),
 		blockcipher.NewCBCMode(c, blockcipher.NewBlock(blockcipher.RandomBytes(16))),
-		blockcipher.NewCTRMode(c),
+		blockcipher.NewCTRMode(c, blockcipher.NewBlock(blockcipher.RandomBytes(16))),
+		blockcipher.NewOFBMode(c, blockcipher.NewBlock(blockcipher.RandomBytes(16))),
+		blockcipher.NewCFBMode(c, blockcipher.NewBlock(blockcipher.RandomBytes(16)), 16),
+		blockcipher.NewCFBMode(c, blockcipher.NewBlock(blockcipher.RandomBytes(16)), 1),
 	} {
 		message := []byte("a secret message")
 		assert.Equal(t, m.Decrypt(m.Encrypt(message)), message)
 	}
 }
+
+// TestParallelModes checks that the parallel modes produce exactly the same output
+// as their serial counterparts, however the input is split up.
+func TestParallelModes(t *testing.T) {
+	c := NewCipher(NewKey(blockcipher.RandomBytes(16)))
+	iv := blockcipher.NewBlock(blockcipher.RandomBytes(16))
+
+	// A counter that is about to overflow makes sure chunks carry into the higher bytes.
+	overflow := blockcipher.Block{0, 0, 0, 0, 0, 0, 0, 0, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xfe}
+
+	for _, p := range []blockcipher.Parallelism{
+		{},
+		{Workers: 1, ChunkBlocks: 1},
+		{Workers: 3, ChunkBlocks: 2},
+		{Workers: 16, ChunkBlocks: 5},
+	} {
+		for _, size := range []int{0, 1, 15, 16, 17, 160, 1000} {
+			message := blockcipher.RandomBytes(size)
+
+			for _, m := range []struct {
+				name             string
+				serial, parallel blockcipher.Mode
+			}{
+				{"ECB", blockcipher.NewECBMode(c), blockcipher.NewParallelECBMode(c, p)},
+				{"CBC", blockcipher.NewCBCMode(c, iv), blockcipher.NewParallelCBCMode(c, iv, p)},
+				{"CTR", blockcipher.NewCTRMode(c, iv), blockcipher.NewParallelCTRMode(c, iv, p)},
+				{"CTR overflow", blockcipher.NewCTRMode(c, overflow), blockcipher.NewParallelCTRMode(c, overflow, p)},
+			} {
+				ciphertext := m.serial.Encrypt(message)
+				assert.Equal(t, ciphertext, m.parallel.Encrypt(message), "%s %+v %d", m.name, p, size)
+				assert.Equal(t, m.serial.Decrypt(ciphertext), m.parallel.Decrypt(ciphertext), "%s %+v %d", m.name, p, size)
+			}
+		}
+	}
+}
+
+func TestBlocks(t *testing.T) {
+	c := NewCipher(NewKey(blockcipher.RandomBytes(16)))
+	src := blockcipher.RandomBytes(5 * 16)
+
+	want := make([]byte, len(src))
+	for i := 0; i < len(src); i += 16 {
+		block := c.Encrypt(blockcipher.NewBlock(src[i : i+16]))
+		copy(want[i:], block[:])
+	}
+
+	dst := make([]byte, len(src))
+	c.EncryptBlocks(dst, src)
+	assert.Equal(t, want, dst)
+
+	c.DecryptBlocks(dst, dst)
+	assert.Equal(t, src, dst, "in place")
+
+	// Ciphers without a batch path fall back to one block at a time.
+	blockcipher.EncryptBlocks(struct{ blockcipher.Cipher }{c}, dst, src)
+	assert.Equal(t, want, dst)
+
+	assert.Panics(t, func() { c.EncryptBlocks(dst, src[:15]) }, "partial block")
+	assert.Panics(t, func() { c.EncryptBlocks(dst[:16], src) }, "short output")
+	assert.Panics(t, func() { c.EncryptBlocks(src[1:], src[:len(src)-16]) }, "partial overlap")
+	assert.NotPanics(t, func() { c.EncryptBlocks(nil, nil) })
+}
+
+func TestByteFault(t *testing.T) {
+	key := NewKey(blockcipher.RandomBytes(16))
+	block := blockcipher.NewBlock(blockcipher.RandomBytes(16))
+	want := NewCipher(key).Encrypt(block)
+
+	// A fault in the output shows up as is.
+	got := NewCipher(key, WithFaultInjector(ByteFault{Round: 10, Step: StepOutput, Index: 6, Mask: 0x80})).Encrypt(block)
+	assert.Equal(t, want[6]^0x80, got[6])
+	got[6] = want[6]
+	assert.Equal(t, want, got)
+
+	// A fault before MixColumns of round 9 spreads to one column,
+	// which the last ShiftRows moves to bytes 0, 7, 10 and 13.
+	got = NewCipher(key, WithFaultInjector(ByteFault{Round: 9, Step: StepShiftRows, Index: 1, Mask: 1})).Encrypt(block)
+	for i := range got {
+		switch i {
+		case 0, 7, 10, 13:
+			assert.NotEqual(t, want[i], got[i], "byte %d", i)
+		default:
+			assert.Equal(t, want[i], got[i], "byte %d", i)
+		}
+	}
+}
+
+func TestMaskedCipher(t *testing.T) {
+	for _, size := range []int{16, 24, 32} {
+		for i := 0; i < 8; i++ {
+			key := NewKey(blockcipher.RandomBytes(size))
+			block := blockcipher.NewBlock(blockcipher.RandomBytes(16))
+
+			c := NewCipher(key)
+			masked := NewMaskedCipher(key)
+
+			assert.Equal(t, c.Encrypt(block), masked.Encrypt(block), "key size %d", size)
+			assert.Equal(t, c.Decrypt(block), masked.Decrypt(block), "key size %d", size)
+		}
+	}
+
+	// The traced state must be masked. It only matches the unmasked cipher
+	// if every mask happens to be zero.
+	key := NewKey(blockcipher.RandomBytes(16))
+	block := blockcipher.NewBlock(blockcipher.RandomBytes(16))
+	var plain, masked bytes.Buffer
+	NewCipher(key, WithTracer(NewFIPSTracer(&plain))).Encrypt(block)
+	NewMaskedCipher(key, WithTracer(NewFIPSTracer(&masked))).Encrypt(block)
+	assert.NotEqual(t, plain.String(), masked.String())
+}
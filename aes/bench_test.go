@@ -0,0 +1,119 @@
+package aes
+
+import (
+	"fmt"
+	"runtime"
+	"testing"
+
+	"github.com/intersesh/crypto/blockcipher"
+)
+
+var benchKeySizes = []int{16, 24, 32}
+
+func BenchmarkNewCipher(b *testing.B) {
+	for _, size := range benchKeySizes {
+		key := NewKey(blockcipher.RandomBytes(size))
+
+		b.Run(fmt.Sprintf("AES-%d", size*8), func(b *testing.B) {
+			b.ReportAllocs()
+			for i := 0; i < b.N; i++ {
+				NewCipher(key)
+			}
+		})
+	}
+}
+
+func BenchmarkEncrypt(b *testing.B) {
+	for _, size := range benchKeySizes {
+		benchmarkBlock(b, fmt.Sprintf("AES-%d", size*8), NewCipher(NewKey(blockcipher.RandomBytes(size))).Encrypt)
+	}
+}
+
+func BenchmarkDecrypt(b *testing.B) {
+	for _, size := range benchKeySizes {
+		key := NewKey(blockcipher.RandomBytes(size))
+
+		benchmarkBlock(b, fmt.Sprintf("AES-%d", size*8), NewCipher(key).Decrypt)
+		benchmarkBlock(b, fmt.Sprintf("AES-%d/equivalent", size*8), NewCipher(key, WithEquivalentInverse()).Decrypt)
+	}
+}
+
+func benchmarkBlock(b *testing.B, name string, op func(blockcipher.Block) blockcipher.Block) {
+	block := blockcipher.NewBlock(blockcipher.RandomBytes(16))
+
+	b.Run(name, func(b *testing.B) {
+		b.SetBytes(16)
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			block = op(block)
+		}
+	})
+}
+
+func BenchmarkModes(b *testing.B) {
+	c := NewCipher(NewKey(blockcipher.RandomBytes(16)))
+	iv := blockcipher.NewBlock(blockcipher.RandomBytes(16))
+
+	modes := []struct {
+		name string
+		mode blockcipher.Mode
+	}{
+		{"ECB", blockcipher.NewECBMode(c)},
+		{"CBC", blockcipher.NewCBCMode(c, iv)},
+		{"CTR", blockcipher.NewCTRMode(c, iv)},
+		{"OFB", blockcipher.NewOFBMode(c, iv)},
+		{"CFB8", blockcipher.NewCFBMode(c, iv, 1)},
+		{"CFB128", blockcipher.NewCFBMode(c, iv, 16)},
+	}
+
+	for _, m := range modes {
+		for _, size := range []int{16, 1024, 16 * 1024} {
+			msg := blockcipher.RandomBytes(size)
+
+			b.Run(fmt.Sprintf("%s/Encrypt/%d", m.name, size), func(b *testing.B) {
+				b.SetBytes(int64(size))
+				b.ReportAllocs()
+				for i := 0; i < b.N; i++ {
+					m.mode.Encrypt(msg)
+				}
+			})
+
+			b.Run(fmt.Sprintf("%s/Decrypt/%d", m.name, size), func(b *testing.B) {
+				b.SetBytes(int64(size))
+				b.ReportAllocs()
+				for i := 0; i < b.N; i++ {
+					m.mode.Decrypt(msg)
+				}
+			})
+		}
+	}
+}
+
+func BenchmarkParallelModes(b *testing.B) {
+	c := NewCipher(NewKey(blockcipher.RandomBytes(16)))
+	iv := blockcipher.NewBlock(blockcipher.RandomBytes(16))
+	msg := blockcipher.RandomBytes(64 * 1024)
+
+	for _, workers := range []int{1, 2, 4, runtime.GOMAXPROCS(0)} {
+		p := blockcipher.Parallelism{Workers: workers}
+
+		modes := []struct {
+			name string
+			op   func([]byte) []byte
+		}{
+			{"ECB/Encrypt", blockcipher.NewParallelECBMode(c, p).Encrypt},
+			{"CBC/Decrypt", blockcipher.NewParallelCBCMode(c, iv, p).Decrypt},
+			{"CTR/Encrypt", blockcipher.NewParallelCTRMode(c, iv, p).Encrypt},
+		}
+
+		for _, m := range modes {
+			b.Run(fmt.Sprintf("%s/workers=%d", m.name, workers), func(b *testing.B) {
+				b.SetBytes(int64(len(msg)))
+				b.ReportAllocs()
+				for i := 0; i < b.N; i++ {
+					m.op(msg)
+				}
+			})
+		}
+	}
+}
@@ -0,0 +1,153 @@
+package aes
+
+import (
+	"bufio"
+	"encoding/hex"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/intersesh/crypto/blockcipher"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// cavpRecord is a single test case from a CAVP response file.
+type cavpRecord struct {
+	encrypt bool
+	fields  map[string][]byte
+}
+
+// parseCAVP reads a NIST CAVP response (.rsp) file.
+// These consist of [ENCRYPT] and [DECRYPT] sections, each holding test cases
+// of 'NAME = value' lines separated by blank lines. Lines starting with '#' are comments.
+func parseCAVP(path string) ([]cavpRecord, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	var (
+		out     []cavpRecord
+		encrypt bool
+		current *cavpRecord
+	)
+
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+
+		switch {
+		case line == "" || strings.HasPrefix(line, "#"):
+			current = nil
+		case line == "[ENCRYPT]" || line == "[DECRYPT]":
+			encrypt = line == "[ENCRYPT]"
+			current = nil
+		default:
+			name, value, ok := strings.Cut(line, " = ")
+			if !ok {
+				return nil, fmt.Errorf("%s: malformed line %q", path, line)
+			}
+
+			if current == nil {
+				out = append(out, cavpRecord{encrypt: encrypt, fields: map[string][]byte{}})
+				current = &out[len(out)-1]
+			}
+
+			if name == "COUNT" {
+				continue
+			}
+
+			decoded, err := hex.DecodeString(value)
+			if err != nil {
+				return nil, fmt.Errorf("%s: field %s: %w", path, name, err)
+			}
+			current.fields[name] = decoded
+		}
+	}
+
+	return out, scanner.Err()
+}
+
+// cavpMode returns the block cipher mode that a response file is named after.
+func cavpMode(name string, c blockcipher.Cipher, iv []byte) blockcipher.Mode {
+	switch name {
+	case "ECB":
+		return blockcipher.NewECBMode(c)
+	case "CBC":
+		return blockcipher.NewCBCMode(c, blockcipher.NewBlock(iv))
+	case "CFB8":
+		return blockcipher.NewCFBMode(c, blockcipher.NewBlock(iv), 1)
+	case "CFB128":
+		return blockcipher.NewCFBMode(c, blockcipher.NewBlock(iv), 16)
+	case "OFB":
+		return blockcipher.NewOFBMode(c, blockcipher.NewBlock(iv))
+	case "CTR":
+		return blockcipher.NewCTRMode(c, blockcipher.NewBlock(iv))
+	}
+
+	panic("unknown mode " + name)
+}
+
+// cavpCrypt runs a single mode operation.
+func cavpCrypt(mode string, encrypt bool, c blockcipher.Cipher, iv, in []byte) []byte {
+	m := cavpMode(mode, c, iv)
+	if encrypt {
+		return m.Encrypt(in)
+	}
+
+	return m.Decrypt(in)
+}
+
+// TestCAVP runs the known answer tests in testdata/cavp against every mode.
+// Files for modes that blockcipher does not implement, such as CFB1,
+// and Monte Carlo tests (MCT) are skipped. See testdata/cavp/README.md.
+func TestCAVP(t *testing.T) {
+	files, err := filepath.Glob("testdata/cavp/*.rsp")
+	require.NoError(t, err)
+	require.NotEmpty(t, files)
+
+	for _, file := range files {
+		file := file
+		name := strings.TrimSuffix(filepath.Base(file), ".rsp")
+
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			mode, test := splitCAVPName(name)
+			if mode == "" {
+				t.Skip("unsupported mode")
+			}
+			if test == "MCT" {
+				t.Skip("Monte Carlo tests are not implemented")
+			}
+
+			records, err := parseCAVP(file)
+			require.NoError(t, err)
+
+			for i, r := range records {
+				in, out := r.fields["PLAINTEXT"], r.fields["CIPHERTEXT"]
+				if !r.encrypt {
+					in, out = out, in
+				}
+
+				assert.Equal(t, out, cavpCrypt(mode, r.encrypt, NewCipher(NewKey(r.fields["KEY"])), r.fields["IV"], in), "record %d", i)
+			}
+		})
+	}
+}
+
+// splitCAVPName splits a file name such as CFB128VarKey256 into its mode and test.
+// The mode is empty if it is not one that cavpMode supports.
+func splitCAVPName(name string) (mode, test string) {
+	for _, m := range []string{"ECB", "CBC", "CFB8", "CFB128", "OFB", "CTR"} {
+		if rest := strings.TrimPrefix(name, m); rest != name {
+			return m, strings.TrimRight(rest, "0123456789")
+		}
+	}
+
+	return "", name
+}
@@ -0,0 +1,49 @@
+package aes
+
+import (
+	"fmt"
+
+	"github.com/intersesh/crypto/matrix"
+)
+
+// FaultInjector simulates faults, such as those caused by voltage glitches or
+// laser pulses, by modifying the state while Encrypt and Decrypt run.
+//
+// Inject is called after the same steps as Tracer.Trace, except for the key
+// schedule steps, and before any tracers see the state. Unlike a Tracer,
+// it may modify the state in place, and the cipher carries on with the result.
+type FaultInjector interface {
+	Inject(round int, step Step, state matrix.Matrix)
+}
+
+// WithFaultInjector makes the cipher call f after every step of Encrypt and Decrypt.
+// It may be given more than once, in which case injectors are called in order.
+func WithFaultInjector(f FaultInjector) Option {
+	return func(c *Cipher) {
+		c.faults = append(c.faults, f)
+	}
+}
+
+// ByteFault is a FaultInjector that XORs Mask into a single byte of the state
+// after the given step of the given round.
+// Index is the position of the byte in the block, so the byte in row Index % 4
+// and column Index / 4 of the state. See FIPS-197 Section 3.4.
+type ByteFault struct {
+	Round int
+	Step  Step
+	Index int
+	Mask  byte
+}
+
+// Inject implements FaultInjector.
+func (f ByteFault) Inject(round int, step Step, state matrix.Matrix) {
+	if round != f.Round || step != f.Step {
+		return
+	}
+
+	if f.Index < 0 || f.Index >= 4*len(state[0]) {
+		panic(fmt.Sprintf("ByteFault: index must be between 0 and %d; received %d", 4*len(state[0])-1, f.Index))
+	}
+
+	state[f.Index%4][f.Index/4] ^= f.Mask
+}
@@ -2,35 +2,11 @@ package aes
 
 import (
 	"fmt"
-	"math/bits"
 
+	"github.com/intersesh/crypto/gf256"
 	"github.com/intersesh/crypto/matrix"
 )
 
-// Mod returns the remainder of the given arguments using division for the
-// field GF(2⁸), as defined in the AES128 paper, FIPS-197 Section 4.2.
-func Mod(dividend, divisor int) int {
-	remainder := dividend
-
-	for remainder != 0 && degree(remainder) >= degree(divisor) {
-		distance := degree(remainder) - degree(divisor)
-		shiftedDivisor := divisor << distance
-		remainder ^= shiftedDivisor
-	}
-
-	return remainder
-}
-
-// degree returns the position of the most significant bit,
-// i.e. the degree of a polynomial in the field GF(2⁸).
-func degree(a int) int {
-	if a == 0 {
-		return -1
-	}
-
-	return bits.Len(uint(a))
-}
-
 func DotProduct(a, b matrix.Vector) byte {
 	if len(a) != len(b) {
 		panic(fmt.Sprintf("vector a has length '%d' and vector b has length of '%d'", len(a), len(b)))
@@ -44,38 +20,23 @@ func DotProduct(a, b matrix.Vector) byte {
 	return out
 }
 
+// Multiply returns the product of a and b in GF(2⁸), see FIPS-197 Section 4.2.
+// It runs in constant time, see gf256.Field.MulConstantTime.
 func Multiply(a, b byte) byte {
-	reduction := byte(0) // Repeatedly matrix.XOR this with positive bits.
-	intermediateXtime := a
-	for i := 0; i < bits.Len(uint(b)); i++ {
-		mask := byte(Exp2(i))
-		isPositive := b&mask > 0
-
-		if isPositive {
-			reduction ^= intermediateXtime
-		}
-
-		intermediateXtime = Xtime(intermediateXtime)
-	}
-	return reduction
+	return byte(gf256.AES.MulConstantTime(gf256.Element(a), gf256.Element(b)))
 }
 
-func Xtime(a byte) byte {
-	mask := 0b10000000
-	maskT := byte(mask)
-	is7Set := a&maskT > 0
-
-	temp := uint16(a << 1)
-
-	if is7Set {
-		temp ^= poly
+// Inverse returns the multiplicative inverse of a in GF(2⁸).
+// As in FIPS-197 Section 5.1.1, 0 is mapped to itself.
+func Inverse(a byte) byte {
+	if a == 0 {
+		return 0
 	}
 
-	outputMask := byte(0b11111111)
-
-	return byte(temp) & outputMask
+	return byte(gf256.AES.Inv(gf256.Element(a)))
 }
 
-func Exp2(i int) int {
-	return 1 << i
+// Xtime multiplies a by x, i.e. {02}, see FIPS-197 Section 4.2.1.
+func Xtime(a byte) byte {
+	return Multiply(a, 0x02)
 }
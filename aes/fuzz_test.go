@@ -0,0 +1,177 @@
+package aes
+
+import (
+	stdaes "crypto/aes"
+	stdcipher "crypto/cipher"
+	"testing"
+
+	"github.com/intersesh/crypto/blockcipher"
+	"github.com/stretchr/testify/assert"
+)
+
+// The fuzz targets in this file compare this package and the blockcipher modes
+// with crypto/aes and crypto/cipher from the standard library.
+// Inputs with an unsupported key or block size are skipped.
+// A seed corpus lives in testdata/fuzz.
+
+func FuzzEncrypt(f *testing.F) {
+	f.Add(make([]byte, 16), make([]byte, 16))
+	f.Add(make([]byte, 24), make([]byte, 16))
+	f.Add(make([]byte, 32), make([]byte, 16))
+
+	f.Fuzz(func(t *testing.T, key, block []byte) {
+		std := newStdCipher(t, key)
+		if len(block) != 16 {
+			t.Skip()
+		}
+
+		want := make([]byte, 16)
+		std.Encrypt(want, block)
+
+		got := NewCipher(NewKey(key)).Encrypt(blockcipher.NewBlock(block))
+		assert.Equal(t, want, got[:])
+	})
+}
+
+func FuzzDecrypt(f *testing.F) {
+	f.Add(make([]byte, 16), make([]byte, 16))
+	f.Add(make([]byte, 24), make([]byte, 16))
+	f.Add(make([]byte, 32), make([]byte, 16))
+
+	f.Fuzz(func(t *testing.T, key, block []byte) {
+		std := newStdCipher(t, key)
+		if len(block) != 16 {
+			t.Skip()
+		}
+
+		want := make([]byte, 16)
+		std.Decrypt(want, block)
+
+		got := NewCipher(NewKey(key)).Decrypt(blockcipher.NewBlock(block))
+		assert.Equal(t, want, got[:])
+
+		got = NewCipher(NewKey(key), WithEquivalentInverse()).Decrypt(blockcipher.NewBlock(block))
+		assert.Equal(t, want, got[:])
+	})
+}
+
+// FuzzECB and FuzzCBC check that messages are padded with PadBytes when they are
+// not a multiple of the block size. The standard library does not pad at all.
+func FuzzECB(f *testing.F) {
+	f.Add(make([]byte, 16), []byte("a secret message"))
+
+	f.Fuzz(func(t *testing.T, key, msg []byte) {
+		std := newStdCipher(t, key)
+		mode := blockcipher.NewECBMode(NewCipher(NewKey(key)))
+
+		padded := pad(msg)
+		want := make([]byte, len(padded))
+		for i := 0; i < len(padded); i += 16 {
+			std.Encrypt(want[i:], padded[i:])
+		}
+
+		assert.Equal(t, want, mode.Encrypt(msg))
+		assert.Equal(t, padded, mode.Decrypt(want))
+	})
+}
+
+func FuzzCBC(f *testing.F) {
+	f.Add(make([]byte, 16), make([]byte, 16), []byte("a secret message"))
+
+	f.Fuzz(func(t *testing.T, key, iv, msg []byte) {
+		std := newStdCipher(t, key)
+		if len(iv) != 16 {
+			t.Skip()
+		}
+		mode := blockcipher.NewCBCMode(NewCipher(NewKey(key)), blockcipher.NewBlock(iv))
+
+		padded := pad(msg)
+		want := make([]byte, len(padded))
+		stdcipher.NewCBCEncrypter(std, iv).CryptBlocks(want, padded)
+
+		assert.Equal(t, want, mode.Encrypt(msg))
+		assert.Equal(t, padded, mode.Decrypt(want))
+	})
+}
+
+// The stream modes must not pad; the ciphertext is always as long as the message.
+func FuzzCTR(f *testing.F) {
+	f.Add(make([]byte, 16), make([]byte, 16), []byte("a secret message"))
+
+	f.Fuzz(func(t *testing.T, key, iv, msg []byte) {
+		std := newStdCipher(t, key)
+		if len(iv) != 16 {
+			t.Skip()
+		}
+		mode := blockcipher.NewCTRMode(NewCipher(NewKey(key)), blockcipher.NewBlock(iv))
+
+		want := make([]byte, len(msg))
+		stdcipher.NewCTR(std, iv).XORKeyStream(want, msg)
+
+		assert.Equal(t, want, mode.Encrypt(msg))
+		assert.Equal(t, msg, mode.Decrypt(want))
+	})
+}
+
+func FuzzOFB(f *testing.F) {
+	f.Add(make([]byte, 16), make([]byte, 16), []byte("a secret message"))
+
+	f.Fuzz(func(t *testing.T, key, iv, msg []byte) {
+		std := newStdCipher(t, key)
+		if len(iv) != 16 {
+			t.Skip()
+		}
+		mode := blockcipher.NewOFBMode(NewCipher(NewKey(key)), blockcipher.NewBlock(iv))
+
+		want := make([]byte, len(msg))
+		stdcipher.NewOFB(std, iv).XORKeyStream(want, msg)
+
+		assert.Equal(t, want, mode.Encrypt(msg))
+		assert.Equal(t, msg, mode.Decrypt(want))
+	})
+}
+
+func FuzzCFB(f *testing.F) {
+	f.Add(make([]byte, 16), make([]byte, 16), []byte("a secret message"))
+
+	f.Fuzz(func(t *testing.T, key, iv, msg []byte) {
+		std := newStdCipher(t, key)
+		if len(iv) != 16 {
+			t.Skip()
+		}
+		mode := blockcipher.NewCFBMode(NewCipher(NewKey(key)), blockcipher.NewBlock(iv), 16)
+
+		want := make([]byte, len(msg))
+		stdcipher.NewCFBEncrypter(std, iv).XORKeyStream(want, msg)
+
+		assert.Equal(t, want, mode.Encrypt(msg))
+		assert.Equal(t, msg, mode.Decrypt(want))
+	})
+}
+
+// newStdCipher returns the standard library's AES cipher for key,
+// or skips the test if key is not a valid AES key.
+func newStdCipher(t *testing.T, key []byte) stdcipher.Block {
+	switch len(key) {
+	case 16, 24, 32:
+	default:
+		t.Skip()
+	}
+
+	c, err := stdaes.NewCipher(key)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return c
+}
+
+// pad pads msg the same way blockcipher.Blockify does.
+func pad(msg []byte) []byte {
+	out := append([]byte{}, msg...)
+	if len(out)%16 == 0 {
+		return out
+	}
+
+	return blockcipher.PadBytes(out, (len(out)/16+1)*16)
+}
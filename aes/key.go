@@ -1,13 +1,21 @@
 package aes
 
-import "fmt"
+import (
+	"fmt"
+	"strings"
+
+	"github.com/intersesh/crypto/matrix"
+)
 
 // Key is a group of 32-bit words that is used to generate a key schedule,
 // which is in turn used to encrypt the state during successive rounds.
 type Key []Word
 
-// NewKey returns
-func NewKey(bytes []byte) []Word {
+// NewKey returns the key made up of the given bytes.
+// Panics unless the key is 16, 24 or 32 bytes long.
+// See NewAES128, NewAES192 and NewAES256 for constructors
+// that check the key length at compile time.
+func NewKey(bytes []byte) Key {
 	l := len(bytes)
 	switch l {
 	default:
@@ -19,9 +27,49 @@ func NewKey(bytes []byte) []Word {
 	return Words(bytes)
 }
 
-func expandKey(key Key, numRounds, wordsInKey, numColumns int) []Word {
+// Bytes returns the key as it was passed to NewKey.
+func (k Key) Bytes() []byte {
+	out := make([]byte, 0, 4*len(k))
+	for _, w := range k {
+		out = append(out, byte(w>>24), byte(w>>16), byte(w>>8), byte(w))
+	}
+
+	return out
+}
+
+// KeySchedule holds the round keys derived from a Key,
+// one word per column of the state and Nb words per round.
+// See FIPS-197 Section 5.2.
+type KeySchedule []Word
+
+// NewKeySchedule runs the AES key expansion for key.
+func NewKeySchedule(key Key) KeySchedule {
+	wordsInKey := len(key)
+	return expandKey(key, 6+wordsInKey, wordsInKey, numColumns)
+}
+
+// Rounds returns the number of rounds the schedule has keys for, i.e. Nr.
+// There is one more round key than rounds, since the zeroth round only adds a key.
+func (s KeySchedule) Rounds() int {
+	return len(s)/numColumns - 1
+}
+
+// RoundKey returns the four words that are added to the state in the given round,
+// with round 0 being the initial AddRoundKey.
+// Panics if the round is not between 0 and Rounds().
+func (s KeySchedule) RoundKey(round int) [4]Word {
+	if round < 0 || round > s.Rounds() {
+		panic(fmt.Sprintf("RoundKey: round must be between 0 and %d; received %d", s.Rounds(), round))
+	}
+
+	var out [4]Word
+	copy(out[:], s[round*numColumns:])
+	return out
+}
+
+func expandKey(key Key, numRounds, wordsInKey, numColumns int) KeySchedule {
 	var (
-		out = make([]Word, numColumns*(numRounds+1))
+		out = make(KeySchedule, numColumns*(numRounds+1))
 		i   int
 	)
 
@@ -31,13 +79,154 @@ func expandKey(key Key, numRounds, wordsInKey, numColumns int) []Word {
 	}
 
 	for i = wordsInKey; i < numColumns*(numRounds+1); i++ {
-		word := out[i-1]
-		if i%wordsInKey == 0 {
-			word = SubstituteWord(RotateWord(word)) ^ Rcon(i/wordsInKey-1)
-		} else if numColumns > 6 && i%numColumns == 4 {
-			word = SubstituteWord(word)
-		}
-		out[i] = out[i-wordsInKey] ^ word
+		out[i] = expandWord(out[i-1], out[i-wordsInKey], i, wordsInKey).Word
+	}
+
+	return out
+}
+
+// KeyExpansionStep holds the intermediate values of computing one word of the
+// key schedule, named after the columns of the tables in FIPS-197 Appendix A.
+type KeyExpansionStep struct {
+	I    int
+	Temp Word
+	// Rotated reports whether i is a multiple of Nk, in which case temp goes
+	// through RotWord, SubWord and the XOR with Rcon[i/Nk].
+	Rotated      bool
+	AfterRotWord Word
+	// Substituted reports whether SubWord was applied, which is also the case
+	// without RotWord for AES-256 when i mod Nk = 4.
+	Substituted  bool
+	AfterSubWord Word
+	Rcon         Word
+	AfterRcon    Word
+	// Previous is w[i-Nk].
+	Previous Word
+	// Word is w[i] = temp ⊕ w[i-Nk].
+	Word Word
+}
+
+// String formats the step as a row of the tables in FIPS-197 Appendix A,
+// leaving the columns that do not apply blank.
+func (s KeyExpansionStep) String() string {
+	columns := [7]string{0: fmt.Sprintf("%08x", uint32(s.Temp))}
+	if s.Rotated {
+		columns[1] = fmt.Sprintf("%08x", uint32(s.AfterRotWord))
+		columns[3] = fmt.Sprintf("%08x", uint32(s.Rcon))
+		columns[4] = fmt.Sprintf("%08x", uint32(s.AfterRcon))
+	}
+	if s.Substituted {
+		columns[2] = fmt.Sprintf("%08x", uint32(s.AfterSubWord))
+	}
+	columns[5] = fmt.Sprintf("%08x", uint32(s.Previous))
+	columns[6] = fmt.Sprintf("%08x", uint32(s.Word))
+
+	out := fmt.Sprintf("%3d", s.I)
+	for _, c := range columns {
+		out += fmt.Sprintf("  %-8s", c)
+	}
+
+	return strings.TrimRight(out, " ")
+}
+
+// KeyExpansion runs the key expansion for key and returns every step after
+// the words of the key itself, i.e. for i from Nk up to Nb(Nr+1)-1.
+// See FIPS-197 Section 5.2 and Appendix A.
+func KeyExpansion(key Key) []KeyExpansionStep {
+	wordsInKey := len(key)
+	schedule := NewKeySchedule(key)
+
+	out := make([]KeyExpansionStep, 0, len(schedule)-wordsInKey)
+	for i := wordsInKey; i < len(schedule); i++ {
+		out = append(out, expandWord(schedule[i-1], schedule[i-wordsInKey], i, wordsInKey))
+	}
+
+	return out
+}
+
+// expandWord computes w[i] from w[i-1] and w[i-Nk], recording every intermediate value.
+func expandWord(previous, back Word, i, wordsInKey int) KeyExpansionStep {
+	s := KeyExpansionStep{I: i, Temp: previous, Previous: back}
+
+	temp := previous
+	switch {
+	case i%wordsInKey == 0:
+		s.Rotated, s.Substituted = true, true
+		s.AfterRotWord = RotateWord(temp)
+		s.AfterSubWord = SubstituteWord(s.AfterRotWord)
+		s.Rcon = Rcon(i/wordsInKey - 1)
+		s.AfterRcon = s.AfterSubWord ^ s.Rcon
+		temp = s.AfterRcon
+	case wordsInKey > 6 && i%wordsInKey == 4:
+		s.Substituted = true
+		s.AfterSubWord = SubstituteWord(temp)
+		temp = s.AfterSubWord
+	}
+
+	s.Word = back ^ temp
+	return s
+}
+
+// RecoverKey runs the key expansion backwards to find the cipher key,
+// given any Nk consecutive words of an AES key schedule and the index of the
+// first of them in the schedule. The key size follows from len(words).
+//
+// For AES-128 the words of a single round key are enough, e.g. the last round key
+// with start 40. For AES-192 and AES-256 a round key only holds 4 of the Nk words,
+// so the words of two consecutive round keys are needed.
+//
+// Every step of expandKey computes w[i] = w[i-Nk] ^ temp, where temp only
+// depends on w[i-1] and i, so w[i-Nk] = w[i] ^ temp can be recovered one word
+// at a time. See FIPS-197 Section 5.2.
+func RecoverKey(words []Word, start int) Key {
+	wordsInKey := len(words)
+	switch wordsInKey {
+	default:
+		panic(fmt.Sprintf("RecoverKey: expected 4, 6 or 8 words; received %d", wordsInKey))
+	case 4, 6, 8:
+		break
+	}
+
+	scheduleLen := numColumns * (wordsInKey + 7)
+	if start < 0 || start+wordsInKey > scheduleLen {
+		panic(fmt.Sprintf("RecoverKey: start must be between 0 and %d; received %d", scheduleLen-wordsInKey, start))
+	}
+
+	schedule := make([]Word, start+wordsInKey)
+	copy(schedule[start:], words)
+
+	for i := start + wordsInKey - 1; i >= wordsInKey; i-- {
+		// With w[i-Nk] = 0, expandWord returns temp itself.
+		temp := expandWord(schedule[i-1], 0, i, wordsInKey).Word
+		schedule[i-wordsInKey] = schedule[i] ^ temp
+	}
+
+	return Key(schedule[:wordsInKey])
+}
+
+// expandKeyInverse derives the key schedule used by the Equivalent Inverse Cipher
+// from a regular key schedule. InvMixColumns is applied to every round key
+// except the first and the last.
+// See KeyExpansionEIC in FIPS-197 Section 5.3.5.
+func expandKeyInverse(schedule []Word, numRounds, numColumns int) []Word {
+	out := make([]Word, len(schedule))
+	copy(out, schedule)
+
+	for i := numColumns; i < numColumns*numRounds; i++ {
+		out[i] = mixColumnWord(out[i], mixColumnPolynomialsInverse)
+	}
+
+	return out
+}
+
+// mixColumnWord applies column mixing to a single word,
+// treating it as one column of the state.
+func mixColumnWord(w Word, polynomials matrix.Matrix) Word {
+	column := matrix.NewVector(uint32(w))
+
+	var out Word
+	for row := 0; row < len(polynomials); row++ {
+		out = out<<8 | Word(DotProduct(matrix.RowVector(polynomials, row), column))
 	}
 
 	return out
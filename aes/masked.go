@@ -0,0 +1,195 @@
+package aes
+
+import (
+	"github.com/intersesh/crypto/blockcipher"
+	"github.com/intersesh/crypto/matrix"
+)
+
+// MaskedCipher is AES with first-order Boolean masking, as a countermeasure
+// against power analysis such as the attacks in the cpa package.
+//
+// Every intermediate value of the state is XORed with a random mask that is
+// drawn anew for every block, so on its own no intermediate value depends on
+// the key and the data. The masks are only combined with the state through
+// XOR, and the state is never unmasked before the ciphertext or plaintext is
+// complete. The key schedule itself is not masked.
+//
+// It produces exactly the same output as Cipher, but is considerably slower,
+// since the S-box has to be recomputed for every block.
+// See 'Power Analysis Attacks' by S. Mangard, E. Oswald and T. Popp, Section 9.2.
+type MaskedCipher struct {
+	c Cipher
+}
+
+// NewMaskedCipher returns a masked cipher for key. Options apply as for NewCipher,
+// except that WithEquivalentInverse has no effect.
+// Tracers, leakage recorders and fault injectors see the masked state,
+// i.e. the values that a device running the cipher would actually handle.
+func NewMaskedCipher(key Key, opts ...Option) MaskedCipher {
+	return MaskedCipher{c: NewCipher(key, opts...)}
+}
+
+// masks holds the random values used to mask a single block.
+type masks struct {
+	// in masks every byte of the state that enters SubBytes, and out every byte
+	// that leaves it. The S-box is recomputed so that it maps x ^ in to S(x) ^ out.
+	in, out byte
+
+	// rows masks each row of the state with a different byte before column mixing,
+	// so that the bytes that MixColumns combines are masked independently.
+	// mixedRows is what column mixing turns these masks into.
+	rows, mixedRows Word
+}
+
+func newMasks(polynomials matrix.Matrix) masks {
+	r := blockcipher.RandomBytes(6)
+	rows := NewWord(r[2:])
+
+	return masks{
+		in:        r[0],
+		out:       r[1],
+		rows:      rows,
+		mixedRows: mixColumnWord(rows, polynomials),
+	}
+}
+
+// Encrypt is Cipher.Encrypt on a masked state.
+// Before every round the state is masked with in, SubBytes swaps in for out,
+// and the state is remasked with the row masks before MixColumns.
+// The round keys are masked so that adding them turns the mixed row masks back into in.
+func (m MaskedCipher) Encrypt(block blockcipher.Block) blockcipher.Block {
+	c := m.c
+	mk := newMasks(mixColumnPolynomials)
+	table := maskedTable(&sbox, mk.in, mk.out)
+	schedule := m.maskedSchedule(mk.mixedRows^uniform(mk.in), uniform(mk.out))
+
+	state := maskState(parse(block), uniform(mk.in))
+	c.step(0, StepInput, state)
+
+	// The state is already masked with in, which the first round key keeps as is.
+	state = addRoundKey(state, c.schedule, 0)
+
+	for round := 1; round < c.numRounds; round++ {
+		c.step(round, StepStart, state)
+		state = substitute(state, &table)
+		c.step(round, StepSubBytes, state)
+		state = shiftRows(state)
+		c.step(round, StepShiftRows, state)
+		state = maskState(state, uniform(mk.out)^mk.rows)
+		state = mixColumns(state, mixColumnPolynomials)
+		c.step(round, StepMixColumns, state)
+		state = addRoundKey(state, schedule, round)
+	}
+
+	c.step(c.numRounds, StepStart, state)
+	state = substitute(state, &table)
+	c.step(c.numRounds, StepSubBytes, state)
+	state = shiftRows(state)
+	c.step(c.numRounds, StepShiftRows, state)
+	state = addRoundKey(state, schedule, c.numRounds)
+	c.step(c.numRounds, StepOutput, state)
+
+	return matrixBlock(state)
+}
+
+// Decrypt is Cipher.Decrypt on a masked state, following the same scheme as
+// Encrypt with the inverse S-box. InvSubBytes swaps in for out, adding the
+// round key swaps out for the row masks, and these are remasked with in
+// after InvMixColumns.
+func (m MaskedCipher) Decrypt(block blockcipher.Block) blockcipher.Block {
+	c := m.c
+	mk := newMasks(mixColumnPolynomialsInverse)
+	table := maskedTable(&sboxInverse, mk.in, mk.out)
+	schedule := m.maskedSchedule(uniform(mk.out)^mk.rows, uniform(mk.out))
+
+	state := maskState(parse(block), uniform(mk.in))
+	c.step(0, StepInverseInput, state)
+
+	// The state is already masked with in, which the last round key keeps as is.
+	state = addRoundKey(state, c.schedule, c.numRounds)
+
+	for round := c.numRounds - 1; round >= 1; round-- {
+		step := c.numRounds - round
+		c.step(step, StepInverseStart, state)
+		state = shiftRowsInverse(state)
+		c.step(step, StepInverseShiftRows, state)
+		state = substitute(state, &table)
+		c.step(step, StepInverseSubBytes, state)
+		state = addRoundKey(state, schedule, round)
+		c.step(step, StepInverseAddRoundKey, state)
+		state = mixColumns(state, mixColumnPolynomialsInverse)
+		state = maskState(state, mk.mixedRows^uniform(mk.in))
+	}
+
+	c.step(c.numRounds, StepInverseStart, state)
+	state = shiftRowsInverse(state)
+	c.step(c.numRounds, StepInverseShiftRows, state)
+	state = substitute(state, &table)
+	c.step(c.numRounds, StepInverseSubBytes, state)
+	state = addRoundKey(state, schedule, 0)
+	c.step(c.numRounds, StepInverseOutput, state)
+
+	return matrixBlock(state)
+}
+
+// maskedSchedule returns a copy of the key schedule where the keys of the
+// intermediate rounds are XORed with inner, and the keys of the first and
+// last round with outer. Encrypt only uses the last round key, and Decrypt
+// only the first, since the other one is added to a state masked with in.
+func (m MaskedCipher) maskedSchedule(inner, outer Word) []Word {
+	out := make([]Word, len(m.c.schedule))
+	for i, w := range m.c.schedule {
+		switch i / numColumns {
+		case 0, m.c.numRounds:
+			out[i] = w ^ outer
+		default:
+			out[i] = w ^ inner
+		}
+	}
+
+	return out
+}
+
+// maskedTable recomputes an S-box, so that it maps x ^ in to table[x] ^ out.
+func maskedTable(table *[256]byte, in, out byte) [256]byte {
+	var masked [256]byte
+	for x := 0; x < 256; x++ {
+		masked[byte(x)^in] = table[x] ^ out
+	}
+
+	return masked
+}
+
+// substitute is like subBytes, but uses the given table.
+func substitute(state matrix.Matrix, table *[256]byte) matrix.Matrix {
+	out := matrix.EmptyMatrix(len(state[0]), 4)
+
+	for row := range state {
+		for col := range state[row] {
+			out[row][col] = table[state[row][col]]
+		}
+	}
+
+	return out
+}
+
+// maskState XORs every column of the state with mask.
+// Masks are always combined before they are applied,
+// so the state is never unmasked in between.
+func maskState(state matrix.Matrix, mask Word) matrix.Matrix {
+	column := matrix.NewVector(uint32(mask))
+	out := matrix.EmptyMatrix(len(state[0]), 4)
+
+	for row := range state {
+		for col := range state[row] {
+			out[row][col] = state[row][col] ^ column[row]
+		}
+	}
+
+	return out
+}
+
+// uniform returns a word that masks every row with the same byte.
+func uniform(b byte) Word {
+	return Word(b) * 0x01010101
+}
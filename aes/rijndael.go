@@ -0,0 +1,106 @@
+package aes
+
+import (
+	"fmt"
+
+	"github.com/intersesh/crypto/matrix"
+)
+
+// Rijndael is the full Rijndael algorithm that AES was standardised from.
+// AES fixes the block size to 128 bits, but Rijndael allows both the block
+// size and the key size to be any multiple of 32 bits between 128 and 256 bits.
+//
+// Since blockcipher.Block is always 128 bits long, Rijndael works on byte slices
+// and does not implement blockcipher.Cipher.
+type Rijndael struct {
+	schedule   []Word
+	numRounds  int
+	numColumns int
+}
+
+// NewRijndael returns a Rijndael cipher for the given key and block size in bytes.
+// Both must be one of 16, 20, 24, 28 or 32 bytes long.
+func NewRijndael(key []byte, blockSize int) Rijndael {
+	if !validRijndaelSize(len(key)) {
+		panic(fmt.Sprintf("aes.NewRijndael: wrong key length: %d", len(key)))
+	}
+
+	if !validRijndaelSize(blockSize) {
+		panic(fmt.Sprintf("aes.NewRijndael: wrong block size: %d", blockSize))
+	}
+
+	wordsInKey := len(key) / 4
+	numColumns := blockSize / 4
+
+	// The number of rounds depends on whichever is larger of the key and the block.
+	// See 'The Design of Rijndael' Section 3.6.
+	numRounds := 6 + wordsInKey
+	if numColumns > wordsInKey {
+		numRounds = 6 + numColumns
+	}
+
+	return Rijndael{
+		schedule:   expandKey(Words(key), numRounds, wordsInKey, numColumns),
+		numRounds:  numRounds,
+		numColumns: numColumns,
+	}
+}
+
+func validRijndaelSize(n int) bool {
+	return n%4 == 0 && n >= 16 && n <= 32
+}
+
+// BlockSize returns the block size of the cipher in bytes.
+func (r Rijndael) BlockSize() int {
+	return r.numColumns * 4
+}
+
+// Encrypt encrypts a single block, which must be exactly BlockSize bytes long.
+// The steps are the same as those of AES; only the dimensions of the state,
+// the row shift offsets and the number of rounds differ.
+func (r Rijndael) Encrypt(block []byte) []byte {
+	state := r.parse(block)
+
+	state = addRoundKey(state, r.schedule, 0)
+
+	for round := 1; round < r.numRounds; round++ {
+		state = subBytes(state)
+		state = shiftRows(state)
+		state = mixColumns(state, mixColumnPolynomials)
+		state = addRoundKey(state, r.schedule, round)
+	}
+
+	state = subBytes(state)
+	state = shiftRows(state)
+	state = addRoundKey(state, r.schedule, r.numRounds)
+
+	return matrixBytes(state)
+}
+
+// Decrypt decrypts a single block, which must be exactly BlockSize bytes long.
+func (r Rijndael) Decrypt(block []byte) []byte {
+	state := r.parse(block)
+
+	state = addRoundKey(state, r.schedule, r.numRounds)
+
+	for round := r.numRounds - 1; round >= 1; round-- {
+		state = shiftRowsInverse(state)
+		state = subBytesInverse(state)
+		state = addRoundKey(state, r.schedule, round)
+		state = mixColumns(state, mixColumnPolynomialsInverse)
+	}
+
+	state = shiftRowsInverse(state)
+	state = subBytesInverse(state)
+	state = addRoundKey(state, r.schedule, 0)
+
+	return matrixBytes(state)
+}
+
+func (r Rijndael) parse(block []byte) matrix.Matrix {
+	if l := len(block); l != r.BlockSize() {
+		panic(fmt.Sprintf("aes.Rijndael: block must be %d bytes long; received %d", r.BlockSize(), l))
+	}
+
+	return parseBytes(block)
+}
@@ -0,0 +1,82 @@
+package aes
+
+import (
+	"encoding/hex"
+	"fmt"
+	"testing"
+
+	"github.com/intersesh/crypto/blockcipher"
+	"github.com/stretchr/testify/assert"
+)
+
+// TestRijndael values taken from the test vectors published alongside the
+// original Rijndael submission to the AES process, where keys and plaintexts
+// are prefixes of the same 256-bit values.
+func TestRijndael(t *testing.T) {
+	var (
+		key, _       = hex.DecodeString("2b7e151628aed2a6abf7158809cf4f3c762e7160f38b4da56a784d9045190cfe")
+		plaintext, _ = hex.DecodeString("3243f6a8885a308d313198a2e03707344a4093822299f31d0082efa98ec4e6c8")
+	)
+
+	// Indexed by block size, then key size.
+	var ciphertexts = map[int]map[int]string{
+		16: {
+			16: "3925841d02dc09fbdc118597196a0b32",
+			20: "231d844639b31b412211cfe93712b880",
+			24: "f9fb29aefc384a250340d833b87ebc00",
+			28: "8faa8fe4dee9eb17caa4797502fc9d3f",
+			32: "1a6e6c2c662e7da6501ffb62bc9e93f3",
+		},
+		20: {
+			16: "16e73aec921314c29df905432bc8968ab64b1f51",
+			20: "0553eb691670dd8a5a5b5addf1aa7450f7a0e587",
+			24: "73cd6f3423036790463aa9e19cfcde894ea16623",
+			28: "601b5dcd1cf4ece954c740445340bf0afdc048df",
+			32: "579e930b36c1529aa3e86628bacfe146942882cf",
+		},
+		24: {
+			16: "b24d275489e82bb8f7375e0d5fcdb1f481757c538b65148a",
+			20: "738dae25620d3d3beff4a037a04290d73eb33521a63ea568",
+			24: "725ae43b5f3161de806a7c93e0bca93c967ec1ae1b71e1cf",
+			28: "bbfc14180afbf6a36382a061843f0b63e769acdc98769130",
+			32: "0ebacf199e3315c2e34b24fcc7c46ef4388aa475d66c194c",
+		},
+		28: {
+			16: "b0a8f78f6b3c66213f792ffd2a61631f79331407a5e5c8d3793aceb1",
+			20: "08b99944edfce33a2acb131183ab0168446b2d15e958480010f545e3",
+			24: "be4c597d8f7efe22a2f7e5b1938e2564d452a5bfe72399c7af1101e2",
+			28: "ef529598ecbce297811b49bbed2c33bbe1241d6e1a833dbe119569e8",
+			32: "02fafc200176ed05deb8edb82a3555b0b10d47a388dfd59cab2f6c11",
+		},
+		32: {
+			16: "7d15479076b69a46ffb3b3beae97ad8313f622f67fedb487de9f06b9ed9c8f19",
+			20: "514f93fb296b5ad16aa7df8b577abcbd484decacccc7fb1f18dc567309ceeffd",
+			24: "5d7101727bb25781bf6715b0e6955282b9610e23a43c2eb062699f0ebf5887b2",
+			28: "d56c5a63627432579e1dd308b2c8f157b40a4bfb56fea1377b25d3ed3d6dbf80",
+			32: "a49406115dfb30a40418aafa4869b7c6a886ff31602a7dd19c889dc64f7e4e7a",
+		},
+	}
+
+	for blockSize, byKey := range ciphertexts {
+		for keySize, want := range byKey {
+			t.Run(fmt.Sprintf("block=%d/key=%d", blockSize*8, keySize*8), func(t *testing.T) {
+				r := NewRijndael(key[:keySize], blockSize)
+
+				ciphertext := r.Encrypt(plaintext[:blockSize])
+				assert.Equal(t, want, hex.EncodeToString(ciphertext))
+				assert.Equal(t, plaintext[:blockSize], r.Decrypt(ciphertext))
+			})
+		}
+	}
+}
+
+// TestRijndaelMatchesAES checks that Rijndael with a 128-bit block is AES.
+func TestRijndaelMatchesAES(t *testing.T) {
+	for _, size := range []int{16, 24, 32} {
+		key := blockcipher.RandomBytes(size)
+		block := blockcipher.NewBlock(blockcipher.RandomBytes(16))
+
+		want := NewCipher(NewKey(key)).Encrypt(block)
+		assert.Equal(t, want[:], NewRijndael(key, 16).Encrypt(block[:]), "key size %d", size)
+	}
+}
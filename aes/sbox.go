@@ -0,0 +1,50 @@
+package aes
+
+import "math/bits"
+
+// SBox computes the S-box, rather than looking it up in the table from FIPS-197 Figure 7.
+// It takes the multiplicative inverse in GF(2⁸) and applies an affine
+// transformation over GF(2) to the result.
+// See FIPS-197 Section 5.1.1.
+func SBox(x byte) byte {
+	return affine(Inverse(x))
+}
+
+// InvSBox computes the inverse S-box, by undoing the affine transformation
+// and then taking the multiplicative inverse.
+// See FIPS-197 Section 5.3.2.
+func InvSBox(x byte) byte {
+	return Inverse(affineInverse(x))
+}
+
+// SBoxTable returns SBox for every byte, as in FIPS-197 Figure 7.
+// Looking values up in it is much faster than calling SBox.
+func SBoxTable() [256]byte {
+	return sbox
+}
+
+// InvSBoxTable returns InvSBox for every byte, as in FIPS-197 Figure 14.
+func InvSBoxTable() [256]byte {
+	return sboxInverse
+}
+
+// sboxTables computes SBox and InvSBox for every byte.
+func sboxTables() (s, inverse [256]byte) {
+	for x := range s {
+		s[x] = SBox(byte(x))
+		inverse[x] = InvSBox(byte(x))
+	}
+
+	return s, inverse
+}
+
+// affine computes bᵢ' = bᵢ ⊕ bᵢ₊₄ ⊕ bᵢ₊₅ ⊕ bᵢ₊₆ ⊕ bᵢ₊₇ ⊕ cᵢ with c = 0x63,
+// where the indices are taken mod 8. Every term is the byte rotated left.
+func affine(b byte) byte {
+	return b ^ bits.RotateLeft8(b, 1) ^ bits.RotateLeft8(b, 2) ^ bits.RotateLeft8(b, 3) ^ bits.RotateLeft8(b, 4) ^ 0x63
+}
+
+// affineInverse is the inverse of affine, with c = 0x05.
+func affineInverse(b byte) byte {
+	return bits.RotateLeft8(b, 1) ^ bits.RotateLeft8(b, 3) ^ bits.RotateLeft8(b, 6) ^ 0x05
+}
@@ -0,0 +1,153 @@
+package aes
+
+import (
+	"fmt"
+	"io"
+
+	"github.com/intersesh/crypto/matrix"
+)
+
+// Step names a point in the cipher at which the state can be observed.
+// The values are the abbreviations used by the examples in FIPS-197 Appendix C.
+type Step string
+
+// Steps of the Cipher function.
+const (
+	StepInput       Step = "input"
+	StepStart       Step = "start"
+	StepSubBytes    Step = "s_box"
+	StepShiftRows   Step = "s_row"
+	StepMixColumns  Step = "m_col"
+	StepKeySchedule Step = "k_sch"
+	StepOutput      Step = "output"
+)
+
+// Steps of the InvCipher and EqInvCipher functions.
+const (
+	StepInverseInput       Step = "iinput"
+	StepInverseStart       Step = "istart"
+	StepInverseSubBytes    Step = "is_box"
+	StepInverseShiftRows   Step = "is_row"
+	StepInverseMixColumns  Step = "im_col"
+	StepInverseKeySchedule Step = "ik_sch"
+	StepInverseAddRoundKey Step = "ik_add"
+	StepInverseOutput      Step = "ioutput"
+)
+
+// Tracer observes the intermediate values of Encrypt and Decrypt.
+//
+// Trace is called with the state after every step of every round.
+// For the key schedule steps, state holds the round key that is about to be
+// added instead, with one word per column.
+// The state must not be modified.
+type Tracer interface {
+	Trace(round int, step Step, state matrix.Matrix)
+}
+
+// WithTracer makes the cipher report every step of Encrypt and Decrypt to t.
+// It may be given more than once, in which case tracers are called in order.
+func WithTracer(t Tracer) Option {
+	return func(c *Cipher) {
+		c.tracers = append(c.tracers, t)
+	}
+}
+
+// step is called by the cipher at every point where the state is traced.
+// It lets the fault injectors modify the state, and then reports the state
+// that the cipher continues with to the tracers.
+func (c Cipher) step(round int, step Step, state matrix.Matrix) {
+	for _, f := range c.faults {
+		f.Inject(round, step, state)
+	}
+
+	for _, t := range c.tracers {
+		t.Trace(round, step, state)
+	}
+}
+
+// traceRoundKey is like step, but only builds the round key matrix
+// when somebody is listening.
+func (c Cipher) traceRoundKey(round int, step Step, schedule []Word, keyRound int) {
+	if len(c.tracers) == 0 {
+		return
+	}
+
+	key := roundKeyMatrix(schedule, keyRound, numColumns)
+	for _, t := range c.tracers {
+		t.Trace(round, step, key)
+	}
+}
+
+// roundKeyMatrix lays out the words of a round key as the columns of a matrix,
+// so that it lines up with the state it is added to.
+func roundKeyMatrix(schedule []Word, round, numColumns int) matrix.Matrix {
+	out := matrix.EmptyMatrix(numColumns, 4)
+	for i := 0; i < numColumns; i++ {
+		out.SetColumn(matrix.NewVector(uint32(schedule[round*numColumns+i])), i)
+	}
+
+	return out
+}
+
+// FIPSTracer is a Tracer that prints every step in the same layout as the
+// examples in FIPS-197 Appendix C, so that its output can be compared with
+// the paper line by line:
+//
+//	round[ 1].start     00102030405060708090a0b0c0d0e0f0
+//	round[ 1].s_box     63cab7040953d051cd60e0e7ba70e18c
+type FIPSTracer struct {
+	w   io.Writer
+	err error
+}
+
+// NewFIPSTracer returns a FIPSTracer that writes to w.
+func NewFIPSTracer(w io.Writer) *FIPSTracer {
+	return &FIPSTracer{w: w}
+}
+
+// Trace implements Tracer.
+func (t *FIPSTracer) Trace(round int, step Step, state matrix.Matrix) {
+	if t.err != nil {
+		return
+	}
+
+	_, t.err = fmt.Fprintf(t.w, "round[%2d].%-10s%x\n", round, step, matrixBytes(state))
+}
+
+// Err returns the first error encountered while writing, if any.
+func (t *FIPSTracer) Err() error {
+	return t.err
+}
+
+// Event is a step observed by a Recorder.
+type Event struct {
+	Round int
+	Step  Step
+	State matrix.Matrix
+}
+
+// Recorder is a Tracer that keeps a copy of every step, so that an encryption
+// or decryption can be replayed or rendered afterwards.
+type Recorder struct {
+	events []Event
+}
+
+// Trace implements Tracer.
+func (r *Recorder) Trace(round int, step Step, state matrix.Matrix) {
+	state = append(matrix.Matrix{}, state...)
+	for i, row := range state {
+		state[i] = append(matrix.Vector{}, row...)
+	}
+
+	r.events = append(r.events, Event{Round: round, Step: step, State: state})
+}
+
+// Events returns every step recorded so far, in order.
+func (r *Recorder) Events() []Event {
+	return r.events
+}
+
+// Reset forgets all recorded steps.
+func (r *Recorder) Reset() {
+	r.events = nil
+}
@@ -0,0 +1,139 @@
+package aes
+
+import (
+	"bytes"
+	"encoding/hex"
+	"fmt"
+	"os"
+	"testing"
+
+	"github.com/intersesh/crypto/blockcipher"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestFIPSTracer reproduces the example vectors from FIPS-197 Appendix C,
+// which are kept in testdata in the same layout as the paper.
+func TestFIPSTracer(t *testing.T) {
+	for _, tc := range []struct {
+		golden string
+		key    string
+	}{
+		{"testdata/fips197_c1.txt", "000102030405060708090a0b0c0d0e0f"},
+		{"testdata/fips197_c2.txt", "000102030405060708090a0b0c0d0e0f1011121314151617"},
+		{"testdata/fips197_c3.txt", "000102030405060708090a0b0c0d0e0f101112131415161718191a1b1c1d1e1f"},
+	} {
+		t.Run(tc.golden, func(t *testing.T) {
+			want, err := os.ReadFile(tc.golden)
+			require.NoError(t, err)
+
+			plaintext, _ := hex.DecodeString("00112233445566778899aabbccddeeff")
+			key, _ := hex.DecodeString(tc.key)
+
+			var got bytes.Buffer
+			fmt.Fprintf(&got, "%-20s%x\n", "PLAINTEXT:", plaintext)
+			fmt.Fprintf(&got, "%-20s%x\n", "KEY:", key)
+
+			tracer := NewFIPSTracer(&got)
+			c := NewCipher(NewKey(key), WithTracer(tracer))
+			eq := NewCipher(NewKey(key), WithTracer(tracer), WithEquivalentInverse())
+
+			fmt.Fprintf(&got, "\nCIPHER (ENCRYPT):\n")
+			ciphertext := c.Encrypt(blockcipher.NewBlock(plaintext))
+
+			fmt.Fprintf(&got, "\nINVERSE CIPHER (DECRYPT):\n")
+			c.Decrypt(ciphertext)
+
+			fmt.Fprintf(&got, "\nEQUIVALENT INVERSE CIPHER (DECRYPT):\n")
+			eq.Decrypt(ciphertext)
+
+			require.NoError(t, tracer.Err())
+			assert.Equal(t, string(want), got.String())
+		})
+	}
+}
+
+// TestLeakageRecorder checks the noiseless leakage against the first round of
+// the example in FIPS-197 Appendix B, where SubBytes outputs d42711aee0bf98f1b8b45de51e415230.
+func TestLeakageRecorder(t *testing.T) {
+	recorder := NewLeakageRecorder(0, 1)
+	c := NewCipher(NewKey([]byte{0x2b, 0x7e, 0x15, 0x16, 0x28, 0xae, 0xd2, 0xa6, 0xab, 0xf7, 0x15, 0x88, 0x09, 0xcf, 0x4f, 0x3c}), WithLeakage(recorder))
+	block := blockcipher.Block{0x32, 0x43, 0xf6, 0xa8, 0x88, 0x5a, 0x30, 0x8d, 0x31, 0x31, 0x98, 0xa2, 0xe0, 0x37, 0x07, 0x34}
+
+	c.Decrypt(c.Encrypt(block))
+	assert.Equal(t, [][]float64{{4, 4, 2, 5, 3, 7, 3, 5, 4, 4, 5, 5, 4, 2, 3, 2}}, recorder.Traces())
+
+	recorder.Reset()
+	assert.Empty(t, recorder.Traces())
+}
+
+// TestRecorder uses the example in FIPS-197 Appendix C.1.
+func TestRecorder(t *testing.T) {
+	key, _ := hex.DecodeString("000102030405060708090a0b0c0d0e0f")
+	plaintext, _ := hex.DecodeString("00112233445566778899aabbccddeeff")
+
+	var recorder Recorder
+	c := NewCipher(NewKey(key), WithTracer(&recorder))
+	c.Encrypt(blockcipher.NewBlock(plaintext))
+
+	// The input and the first round key, five steps for each of the first nine
+	// rounds, and the last round without MixColumns but with the output.
+	events := recorder.Events()
+	require.Len(t, events, 2+5*9+5)
+
+	assert.Equal(t, Event{Round: 0, Step: StepInput, State: parse(blockcipher.NewBlock(plaintext))}, events[0])
+	assert.Equal(t, StepKeySchedule, events[1].Step)
+	assert.Equal(t, Event{Round: 1, Step: StepSubBytes, State: parse(mustBlock("63cab7040953d051cd60e0e7ba70e18c"))}, events[3])
+
+	last := events[len(events)-1]
+	assert.Equal(t, StepOutput, last.Step)
+	assert.Equal(t, "69c4e0d86a7b0430d8cdb78070b4c55a", hex.EncodeToString(matrixBytes(last.State)))
+
+	// The recorded states are copies, which later steps do not modify.
+	assert.NotEqual(t, events[2].State, events[3].State)
+
+	recorder.Reset()
+	assert.Empty(t, recorder.Events())
+}
+
+func mustBlock(s string) blockcipher.Block {
+	b, err := hex.DecodeString(s)
+	if err != nil {
+		panic(err)
+	}
+
+	return blockcipher.NewBlock(b)
+}
+
+// TestKeyExpansion reproduces the tables in FIPS-197 Appendix A, which are kept
+// in testdata with one row per word. The columns are i, temp, after RotWord(),
+// after SubWord(), Rcon[i/Nk], after XOR with Rcon, w[i-Nk] and w[i].
+func TestKeyExpansion(t *testing.T) {
+	for _, tc := range []struct {
+		golden string
+		key    string
+	}{
+		{"testdata/fips197_a1.txt", "2b7e151628aed2a6abf7158809cf4f3c"},
+		{"testdata/fips197_a2.txt", "8e73b0f7da0e6452c810f32b809079e562f8ead2522c6b7b"},
+		{"testdata/fips197_a3.txt", "603deb1015ca71be2b73aef0857d77811f352c073b6108d72d9810a30914dff4"},
+	} {
+		t.Run(tc.golden, func(t *testing.T) {
+			want, err := os.ReadFile(tc.golden)
+			require.NoError(t, err)
+
+			key, _ := hex.DecodeString(tc.key)
+			steps := KeyExpansion(NewKey(key))
+
+			var got bytes.Buffer
+			for _, s := range steps {
+				fmt.Fprintln(&got, s)
+			}
+			assert.Equal(t, string(want), got.String())
+
+			schedule := NewKeySchedule(NewKey(key))
+			for _, s := range steps {
+				assert.Equal(t, schedule[s.I], s.Word)
+			}
+		})
+	}
+}
@@ -0,0 +1,129 @@
+package aes
+
+import (
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/intersesh/crypto/blockcipher"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// wycheproofFile is the layout shared by all Project Wycheproof test vector files.
+// Only the shape of the test groups differs between constructions.
+type wycheproofFile[G any] struct {
+	Algorithm     string `json:"algorithm"`
+	NumberOfTests int    `json:"numberOfTests"`
+	Notes         map[string]struct {
+		Description string `json:"description"`
+	} `json:"notes"`
+	TestGroups []G `json:"testGroups"`
+}
+
+// wycheproofTest holds the fields that every test case has.
+type wycheproofTest struct {
+	TcID    int      `json:"tcId"`
+	Comment string   `json:"comment"`
+	Flags   []string `json:"flags"`
+	Result  string   `json:"result"`
+}
+
+// wycheproofHex is a hex encoded byte slice.
+type wycheproofHex []byte
+
+func (h *wycheproofHex) UnmarshalJSON(b []byte) error {
+	var s string
+	if err := json.Unmarshal(b, &s); err != nil {
+		return err
+	}
+
+	decoded, err := hex.DecodeString(s)
+	*h = decoded
+	return err
+}
+
+// loadWycheproof decodes a file from testdata/wycheproof.
+func loadWycheproof[G any](t *testing.T, name string) wycheproofFile[G] {
+	t.Helper()
+
+	b, err := os.ReadFile(filepath.Join("testdata/wycheproof", name))
+	require.NoError(t, err)
+
+	var f wycheproofFile[G]
+	require.NoError(t, json.Unmarshal(b, &f))
+	return f
+}
+
+// checkWycheproof runs a single test case as a subtest named after its tcId and
+// expected result. The check function reports whether the construction accepted
+// the input and produced the expected output; valid tests must pass, invalid tests
+// must be rejected, and acceptable tests may go either way.
+func checkWycheproof[G any](t *testing.T, f wycheproofFile[G], tc wycheproofTest, check func() bool) {
+	t.Helper()
+
+	t.Run(fmt.Sprintf("tcId=%d/%s", tc.TcID, tc.Result), func(t *testing.T) {
+		ok := check()
+
+		var notes []string
+		for _, flag := range tc.Flags {
+			notes = append(notes, fmt.Sprintf("%s: %s", flag, f.Notes[flag].Description))
+		}
+
+		switch tc.Result {
+		case "valid":
+			assert.True(t, ok, "%s: %q rejected\n%v", f.Algorithm, tc.Comment, notes)
+		case "invalid":
+			assert.False(t, ok, "%s: %q accepted\n%v", f.Algorithm, tc.Comment, notes)
+		case "acceptable":
+			t.Logf("%s: %q accepted=%t", f.Algorithm, tc.Comment, ok)
+		default:
+			t.Fatalf("unknown result %q", tc.Result)
+		}
+	})
+}
+
+// TestWycheproof runs the Project Wycheproof vectors for every construction in this
+// repository that Wycheproof has vectors for. The files are vendored in testdata/wycheproof.
+func TestWycheproof(t *testing.T) {
+	t.Run("aes_cbc_pkcs5_test.json", testWycheproofCBCPKCS5)
+}
+
+func testWycheproofCBCPKCS5(t *testing.T) {
+	type group struct {
+		Tests []struct {
+			wycheproofTest
+			Key wycheproofHex `json:"key"`
+			IV  wycheproofHex `json:"iv"`
+			Msg wycheproofHex `json:"msg"`
+			CT  wycheproofHex `json:"ct"`
+		} `json:"tests"`
+	}
+
+	f := loadWycheproof[group](t, "aes_cbc_pkcs5_test.json")
+
+	var count int
+	for _, g := range f.TestGroups {
+		for _, tc := range g.Tests {
+			tc := tc
+			count++
+
+			checkWycheproof(t, f, tc.wycheproofTest, func() bool {
+				mode := blockcipher.NewCBCMode(NewCipher(NewKey(tc.Key)), blockcipher.NewBlock(tc.IV))
+
+				plaintext, err := blockcipher.UnpadBytes(mode.Decrypt(tc.CT), 16)
+				if err != nil || !assert.ObjectsAreEqual([]byte(tc.Msg), plaintext) {
+					return false
+				}
+
+				padded := blockcipher.PadBytes(append([]byte{}, tc.Msg...), (len(tc.Msg)/16+1)*16)
+				return assert.ObjectsAreEqual([]byte(tc.CT), mode.Encrypt(padded))
+			})
+		}
+	}
+
+	assert.Equal(t, f.NumberOfTests, count)
+}
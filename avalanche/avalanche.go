@@ -0,0 +1,292 @@
+// Package avalanche measures how quickly a change in the plaintext or the key
+// spreads through the rounds of the cipher in the aes package.
+//
+// Flipping a single input bit should, after enough rounds, flip every output
+// bit with probability 1/2 (the strict avalanche criterion, SAC), independently
+// of the other output bits (the bit independence criterion, BIC).
+// See A. F. Webster and S. E. Tavares, 'On the Design of S-Boxes', CRYPTO '85.
+package avalanche
+
+import (
+	"fmt"
+	"math"
+	"math/bits"
+	"math/rand"
+
+	"github.com/intersesh/crypto/aes"
+	"github.com/intersesh/crypto/blockcipher"
+	"github.com/intersesh/crypto/matrix"
+)
+
+// Input selects which input bits are flipped.
+type Input int
+
+const (
+	// Plaintext flips each of the 128 bits of the plaintext.
+	Plaintext Input = iota
+	// Key flips each bit of the key.
+	Key
+)
+
+func (in Input) String() string {
+	switch in {
+	case Plaintext:
+		return "plaintext"
+	case Key:
+		return "key"
+	default:
+		return fmt.Sprintf("Input(%d)", int(in))
+	}
+}
+
+// Config describes an experiment.
+type Config struct {
+	Input Input
+	// KeySize is the size of the key in bytes: 16, 24 or 32.
+	KeySize int
+	// Rounds overrides the number of rounds, see aes.WithRounds.
+	// Zero means the number of rounds prescribed for KeySize.
+	Rounds int
+	// Samples is the number of random plaintext and key pairs
+	// for which every input bit is flipped.
+	Samples int
+	// Seed seeds the generator that draws the samples.
+	Seed int64
+}
+
+// Round holds the measurements of the state after one round.
+type Round struct {
+	Round int
+	// SAC[i][j] is the fraction of samples in which flipping input bit i
+	// flipped bit j of the state. Bit 0 is the most significant bit of byte 0.
+	SAC [][]float64
+	// BIC is the largest absolute correlation between the flips of two bits
+	// of the state, for any flipped input bit. Pairs in which either bit
+	// flipped in all samples or in none are left out, as their correlation
+	// is undefined.
+	BIC float64
+	// Distances[d] counts the flips that changed exactly d bits of the state.
+	Distances [blockBits + 1]int
+}
+
+// Result holds the measurements after every round, starting with round 1.
+type Result struct {
+	Config Config
+	Rounds []Round
+}
+
+const blockBits = 8 * len(blockcipher.Block{})
+
+// Measure runs an experiment. Panics if the key size is invalid or there are no samples.
+func Measure(cfg Config) *Result {
+	if cfg.KeySize != 16 && cfg.KeySize != 24 && cfg.KeySize != 32 {
+		panic(fmt.Sprintf("Measure: key must be 16, 24 or 32 bytes; received %d", cfg.KeySize))
+	}
+	if cfg.Samples < 1 {
+		panic(fmt.Sprintf("Measure: need at least 1 sample; received %d", cfg.Samples))
+	}
+
+	var opts []aes.Option
+	if cfg.Rounds != 0 {
+		opts = append(opts, aes.WithRounds(cfg.Rounds))
+	}
+
+	inputBits := blockBits
+	if cfg.Input == Key {
+		inputBits = 8 * cfg.KeySize
+	}
+
+	rng := rand.New(rand.NewSource(cfg.Seed))
+	samples := make([]sample, cfg.Samples)
+	for i := range samples {
+		s := &samples[i]
+		s.key = make([]byte, cfg.KeySize)
+		rng.Read(s.key)
+		rng.Read(s.plaintext[:])
+		s.states = encrypt(s.key, s.plaintext, opts)
+	}
+
+	numRounds := len(samples[0].states)
+	r := &Result{Config: cfg, Rounds: make([]Round, numRounds)}
+	for i := range r.Rounds {
+		r.Rounds[i].Round = i + 1
+		r.Rounds[i].SAC = make([][]float64, inputBits)
+	}
+
+	// flips[round][j] has bit n set if bit j of the state flipped in sample n.
+	words := (cfg.Samples + 63) / 64
+	flips := make([][blockBits][]uint64, numRounds)
+	for round := range flips {
+		for j := range flips[round] {
+			flips[round][j] = make([]uint64, words)
+		}
+	}
+
+	for bit := 0; bit < inputBits; bit++ {
+		for round := range flips {
+			for j := range flips[round] {
+				for w := range flips[round][j] {
+					flips[round][j][w] = 0
+				}
+			}
+		}
+
+		for n, s := range samples {
+			key, plaintext := s.key, s.plaintext
+			if cfg.Input == Key {
+				key = append([]byte{}, key...)
+				key[bit/8] ^= 0x80 >> (bit % 8)
+			} else {
+				plaintext[bit/8] ^= 0x80 >> (bit % 8)
+			}
+
+			for round, state := range encrypt(key, plaintext, opts) {
+				distance := 0
+				for j := 0; j < blockBits; j++ {
+					if (state[j/8]^s.states[round][j/8])&(0x80>>(j%8)) != 0 {
+						flips[round][j][n/64] |= 1 << (n % 64)
+						distance++
+					}
+				}
+				r.Rounds[round].Distances[distance]++
+			}
+		}
+
+		for round := range r.Rounds {
+			rr := &r.Rounds[round]
+			rr.SAC[bit] = make([]float64, blockBits)
+
+			var counts [blockBits]int
+			for j := range counts {
+				counts[j] = popCount(flips[round][j])
+				rr.SAC[bit][j] = float64(counts[j]) / float64(cfg.Samples)
+			}
+
+			if bic := independence(flips[round], counts, cfg.Samples); bic > rr.BIC {
+				rr.BIC = bic
+			}
+		}
+	}
+
+	return r
+}
+
+type sample struct {
+	key       []byte
+	plaintext blockcipher.Block
+	states    [][16]byte
+}
+
+// encrypt returns the state after every round.
+func encrypt(key []byte, plaintext blockcipher.Block, opts []aes.Option) [][16]byte {
+	var t roundTracer
+	c := aes.NewCipher(aes.NewKey(key), append(opts[:len(opts):len(opts)], aes.WithTracer(&t))...)
+	c.Encrypt(plaintext)
+
+	return t.states
+}
+
+// roundTracer records the state at the end of every round: the start of the
+// next round, or the output after the last one.
+type roundTracer struct {
+	states [][16]byte
+}
+
+func (t *roundTracer) Trace(round int, step aes.Step, state matrix.Matrix) {
+	if (step != aes.StepStart || round < 2) && step != aes.StepOutput {
+		return
+	}
+
+	var b [16]byte
+	for column := 0; column < 4; column++ {
+		for row := 0; row < 4; row++ {
+			b[4*column+row] = state[row][column]
+		}
+	}
+	t.states = append(t.states, b)
+}
+
+// independence returns the largest absolute Pearson correlation between the
+// flips of any two bits of the state.
+func independence(flips [blockBits][]uint64, counts [blockBits]int, samples int) float64 {
+	n := float64(samples)
+
+	out := 0.0
+	for j := 0; j < blockBits; j++ {
+		if counts[j] == 0 || counts[j] == samples {
+			continue
+		}
+
+		for k := j + 1; k < blockBits; k++ {
+			if counts[k] == 0 || counts[k] == samples {
+				continue
+			}
+
+			both := 0
+			for w := range flips[j] {
+				both += bits.OnesCount64(flips[j][w] & flips[k][w])
+			}
+
+			sj, sk := float64(counts[j]), float64(counts[k])
+			corr := (n*float64(both) - sj*sk) / math.Sqrt((n*sj-sj*sj)*(n*sk-sk*sk))
+			if math.Abs(corr) > out {
+				out = math.Abs(corr)
+			}
+		}
+	}
+
+	return out
+}
+
+func popCount(words []uint64) int {
+	out := 0
+	for _, w := range words {
+		out += bits.OnesCount64(w)
+	}
+
+	return out
+}
+
+// MeanDistance returns the average number of state bits changed by a flip.
+// It approaches 64 once the round provides full diffusion.
+func (r Round) MeanDistance() float64 {
+	total, count := 0, 0
+	for d, n := range r.Distances {
+		total += d * n
+		count += n
+	}
+
+	return float64(total) / float64(count)
+}
+
+// Dependence returns the fraction of pairs of input and state bits for which
+// flipping the input bit flipped the state bit in at least one sample.
+// A value of 1 means every state bit depends on every input bit.
+func (r Round) Dependence() float64 {
+	dependent, count := 0, 0
+	for _, row := range r.SAC {
+		for _, p := range row {
+			if p > 0 {
+				dependent++
+			}
+			count++
+		}
+	}
+
+	return float64(dependent) / float64(count)
+}
+
+// SACDeviation returns the largest absolute difference between an entry of
+// the SAC matrix and the ideal 1/2.
+func (r Round) SACDeviation() float64 {
+	out := 0.0
+	for _, row := range r.SAC {
+		for _, p := range row {
+			if d := math.Abs(p - 0.5); d > out {
+				out = d
+			}
+		}
+	}
+
+	return out
+}
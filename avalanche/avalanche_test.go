@@ -0,0 +1,81 @@
+package avalanche
+
+import (
+	"bytes"
+	"encoding/csv"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPlaintext(t *testing.T) {
+	r := Measure(Config{Input: Plaintext, KeySize: 16, Rounds: 3, Samples: 64, Seed: 1})
+	require.Len(t, r.Rounds, 3)
+
+	for _, round := range r.Rounds {
+		total := 0
+		for _, n := range round.Distances {
+			total += n
+		}
+		assert.Equal(t, 64*128, total)
+	}
+
+	// After one round, a flipped byte has only spread to its column,
+	// i.e. a quarter of the state.
+	first := r.Rounds[0]
+	assert.Equal(t, 1, first.Round)
+	assert.Equal(t, 0.25, first.Dependence())
+	assert.Equal(t, 0.5, first.SACDeviation())
+	assert.Equal(t, 0.0, first.SAC[0][127])
+
+	// After two rounds, every bit depends on every input bit, but MixColumns
+	// turns a difference in a single byte into (2δ, δ, δ, 3δ), so the middle
+	// two bytes of every column still flip together.
+	second := r.Rounds[1]
+	assert.Equal(t, 1.0, second.Dependence())
+	assert.InDelta(t, 1.0, second.BIC, 1e-9)
+
+	third := r.Rounds[2]
+	assert.InDelta(t, 64, third.MeanDistance(), 1)
+	assert.Less(t, third.SACDeviation(), 0.5)
+	assert.Less(t, third.BIC, 0.9)
+}
+
+func TestKey(t *testing.T) {
+	r := Measure(Config{Input: Key, KeySize: 24, Rounds: 2, Samples: 16, Seed: 1})
+	require.Len(t, r.Rounds, 2)
+	assert.Len(t, r.Rounds[0].SAC, 192)
+	assert.Len(t, r.Rounds[0].SAC[0], 128)
+	assert.Less(t, r.Rounds[0].Dependence(), 1.0)
+
+	assert.Panics(t, func() { Measure(Config{KeySize: 20, Samples: 1}) })
+	assert.Panics(t, func() { Measure(Config{KeySize: 16}) })
+}
+
+func TestOutput(t *testing.T) {
+	r := Measure(Config{Input: Plaintext, KeySize: 16, Rounds: 2, Samples: 8, Seed: 1})
+
+	var buf bytes.Buffer
+	require.NoError(t, r.WriteCSV(&buf))
+	records, err := csv.NewReader(&buf).ReadAll()
+	require.NoError(t, err)
+	assert.Len(t, records, 1+2*128*128)
+	assert.Equal(t, []string{"round", "input_bit", "output_bit", "probability"}, records[0])
+	assert.Equal(t, []string{"1", "0", "127", "0.0000"}, records[128])
+
+	buf.Reset()
+	require.NoError(t, r.WriteDistancesCSV(&buf))
+	records, err = csv.NewReader(&buf).ReadAll()
+	require.NoError(t, err)
+	assert.Len(t, records, 1+2*129)
+
+	summary := strings.Split(strings.TrimSpace(r.Summary()), "\n")
+	assert.Len(t, summary, 4)
+	assert.Equal(t, "8 samples, flipping every plaintext bit", summary[0])
+
+	heatmap := strings.Split(strings.TrimSpace(r.Rounds[0].Heatmap()), "\n")
+	require.Len(t, heatmap, 17)
+	assert.Equal(t, " 0 |@@@@            |", heatmap[1])
+}
@@ -0,0 +1,116 @@
+package avalanche
+
+import (
+	"encoding/csv"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+)
+
+// WriteCSV writes the SAC matrix of every round in long format, with the header
+// round,input_bit,output_bit,probability, which most plotting tools can turn
+// into one heatmap per round.
+func (r *Result) WriteCSV(w io.Writer) error {
+	out := csv.NewWriter(w)
+	if err := out.Write([]string{"round", "input_bit", "output_bit", "probability"}); err != nil {
+		return err
+	}
+
+	for _, round := range r.Rounds {
+		for i, row := range round.SAC {
+			for j, p := range row {
+				record := []string{
+					strconv.Itoa(round.Round),
+					strconv.Itoa(i),
+					strconv.Itoa(j),
+					strconv.FormatFloat(p, 'f', 4, 64),
+				}
+				if err := out.Write(record); err != nil {
+					return err
+				}
+			}
+		}
+	}
+
+	out.Flush()
+	return out.Error()
+}
+
+// WriteDistancesCSV writes the Hamming distance distribution of every round,
+// with the header round,distance,count.
+func (r *Result) WriteDistancesCSV(w io.Writer) error {
+	out := csv.NewWriter(w)
+	if err := out.Write([]string{"round", "distance", "count"}); err != nil {
+		return err
+	}
+
+	for _, round := range r.Rounds {
+		for d, n := range round.Distances {
+			if err := out.Write([]string{strconv.Itoa(round.Round), strconv.Itoa(d), strconv.Itoa(n)}); err != nil {
+				return err
+			}
+		}
+	}
+
+	out.Flush()
+	return out.Error()
+}
+
+// Summary returns a table with one line per round, for example for
+// `aes avalanche -samples 200`:
+//
+//	200 samples, flipping every plaintext bit
+//	round  mean distance  dependence  SAC deviation    BIC
+//	    1          16.21       0.250          0.500  1.000
+//	    2          64.25       1.000          0.140  1.000
+//	    3          63.98       1.000          0.155  0.350
+func (r *Result) Summary() string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "%d samples, flipping every %s bit\n", r.Config.Samples, r.Config.Input)
+	fmt.Fprintf(&b, "round  mean distance  dependence  SAC deviation    BIC\n")
+	for _, round := range r.Rounds {
+		fmt.Fprintf(&b, "%5d  %13.2f  %10.3f  %13.3f  %5.3f\n",
+			round.Round, round.MeanDistance(), round.Dependence(), round.SACDeviation(), round.BIC)
+	}
+
+	return b.String()
+}
+
+// shades go from a flip probability of 0 to 1/2 or more.
+const shades = " .:-=+*#%@"
+
+// Heatmap returns the SAC matrix of a round at byte granularity: the character
+// in row i and column j shows how likely a flip in input byte i is to flip a
+// bit in byte j of the state, from ' ' for never to '@' for one half.
+func (r Round) Heatmap() string {
+	inputBytes := len(r.SAC) / 8
+
+	var b strings.Builder
+	b.WriteString("    ")
+	for j := 0; j < blockBits/8; j++ {
+		fmt.Fprintf(&b, "%x", j%16)
+	}
+	b.WriteString("\n")
+
+	for i := 0; i < inputBytes; i++ {
+		fmt.Fprintf(&b, "%2d |", i)
+		for j := 0; j < blockBits/8; j++ {
+			sum := 0.0
+			for x := 8 * i; x < 8*i+8; x++ {
+				for y := 8 * j; y < 8*j+8; y++ {
+					sum += r.SAC[x][y]
+				}
+			}
+
+			shade := int(sum / 64 * 2 * float64(len(shades)))
+			if shade >= len(shades) {
+				shade = len(shades) - 1
+			}
+			b.WriteByte(shades[shade])
+		}
+		b.WriteString("|\n")
+	}
+
+	return b.String()
+}
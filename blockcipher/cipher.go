@@ -1,6 +1,84 @@
 package blockcipher
 
+import (
+	"fmt"
+	"unsafe"
+)
+
 type Cipher interface {
 	Encrypt(block Block) Block
 	Decrypt(block Block) Block
 }
+
+// BatchCipher is a Cipher that can also process many consecutive blocks in one call,
+// which saves converting every block to and from a Block value and gives
+// implementations room to pipeline several blocks at once.
+//
+// EncryptBlocks and DecryptBlocks must accept any src whose length is a multiple
+// of 16 bytes, and a dst at least as long as src. The two may overlap exactly,
+// to encrypt or decrypt in place, but not partially. See CheckBlocks.
+type BatchCipher interface {
+	Cipher
+	EncryptBlocks(dst, src []byte)
+	DecryptBlocks(dst, src []byte)
+}
+
+// EncryptBlocks encrypts the blocks in src into dst, using the batch path of
+// the cipher if it has one, and one block at a time otherwise.
+func EncryptBlocks(cipher Cipher, dst, src []byte) {
+	if b, ok := cipher.(BatchCipher); ok {
+		b.EncryptBlocks(dst, src)
+		return
+	}
+
+	cryptBlocks(cipher.Encrypt, dst, src)
+}
+
+// DecryptBlocks is the inverse of EncryptBlocks.
+func DecryptBlocks(cipher Cipher, dst, src []byte) {
+	if b, ok := cipher.(BatchCipher); ok {
+		b.DecryptBlocks(dst, src)
+		return
+	}
+
+	cryptBlocks(cipher.Decrypt, dst, src)
+}
+
+func cryptBlocks(crypt func(Block) Block, dst, src []byte) {
+	CheckBlocks(dst, src)
+
+	for i := 0; i < len(src); i += 16 {
+		block := crypt(NewBlock(src[i : i+16]))
+		copy(dst[i:], block[:])
+	}
+}
+
+// CheckBlocks panics unless src is a whole number of blocks, dst is large enough
+// to hold them, and the two either do not overlap or start at the same address.
+// Implementations of BatchCipher call it before touching either buffer.
+func CheckBlocks(dst, src []byte) {
+	if len(src)%16 != 0 {
+		panic(fmt.Sprintf("CheckBlocks: input must be a multiple of 16 bytes; received %d", len(src)))
+	}
+
+	if len(dst) < len(src) {
+		panic(fmt.Sprintf("CheckBlocks: output is shorter than input; received %d < %d", len(dst), len(src)))
+	}
+
+	if inexactOverlap(dst[:len(src)], src) {
+		panic("CheckBlocks: input and output overlap, but do not start at the same address")
+	}
+}
+
+// inexactOverlap reports whether x and y share memory at any offset other than
+// their first element. Go has no way to compare the addresses of two slices
+// without unsafe; this is the same check that crypto/cipher does internally.
+func inexactOverlap(x, y []byte) bool {
+	if len(x) == 0 || len(y) == 0 || &x[0] == &y[0] {
+		return false
+	}
+
+	xStart := uintptr(unsafe.Pointer(&x[0]))
+	yStart := uintptr(unsafe.Pointer(&y[0]))
+	return xStart <= yStart+uintptr(len(y)-1) && yStart <= xStart+uintptr(len(x)-1)
+}
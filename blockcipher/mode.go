@@ -3,6 +3,8 @@ package blockcipher
 import (
 	"crypto/rand"
 	"encoding/binary"
+	"errors"
+	"fmt"
 	"log"
 )
 
@@ -22,21 +24,16 @@ type ecb struct {
 }
 
 func (e *ecb) Encrypt(bytes []byte) []byte {
-	return doECB(e.cipher.Encrypt, bytes)
-}
-func (e *ecb) Decrypt(bytes []byte) []byte {
-	return doECB(e.cipher.Decrypt, bytes)
+	padded := padToBlocks(bytes, 16)
+	out := make([]byte, len(padded))
+	EncryptBlocks(e.cipher, out, padded)
+	return out
 }
 
-func doECB(crypt func(Block) Block, bytes []byte) []byte {
-	blocks := Blockify(bytes, 16)
-	var out []byte
-
-	for _, b := range blocks {
-		block := crypt(b)
-		out = append(out, block[:]...)
-	}
-
+func (e *ecb) Decrypt(bytes []byte) []byte {
+	padded := padToBlocks(bytes, 16)
+	out := make([]byte, len(padded))
+	DecryptBlocks(e.cipher, out, padded)
 	return out
 }
 
@@ -52,74 +49,204 @@ type cbc struct {
 	cipher Cipher
 }
 
+// Encrypt XORs every block with the previous ciphertext block before encrypting it,
+// so the blocks are encrypted in place one at a time.
 func (c *cbc) Encrypt(bytes []byte) []byte {
-	blocks := Blockify(bytes, 16)
-	var out []byte
-	prevBlock := c.iv
-
-	for _, b := range blocks {
-		encrypted := c.cipher.Encrypt(Block(XOR(b[:], prevBlock[:])))
-		prevBlock = encrypted
-		out = append(out, encrypted[:]...)
+	padded := padToBlocks(bytes, 16)
+	out := make([]byte, len(padded))
+	prevBlock := c.iv[:]
+
+	for i := 0; i < len(padded); i += 16 {
+		block := out[i : i+16]
+		xorBytes(block, padded[i:i+16], prevBlock)
+		EncryptBlocks(c.cipher, block, block)
+		prevBlock = block
 	}
 
 	return out
 }
+
+// Decrypt only needs the ciphertext to undo the chaining,
+// so all blocks are decrypted in a single batch first.
 func (c *cbc) Decrypt(bytes []byte) []byte {
-	blocks := Blockify(bytes, 16)
-	var out []byte
-	prevBlock := c.iv
+	padded := padToBlocks(bytes, 16)
+	out := make([]byte, len(padded))
+	DecryptBlocks(c.cipher, out, padded)
+	c.unchain(out, padded, 0, len(padded)/16)
+	return out
+}
 
-	for _, b := range blocks {
-		block := c.cipher.Decrypt(b)
-		decrypted := XOR(block[:], prevBlock[:])
-		prevBlock = b
-		out = append(out, decrypted[:]...)
+// unchain XORs the decrypted blocks in [start, end) with the ciphertext block
+// that precedes them, or with the IV for the first block.
+func (c *cbc) unchain(out, ciphertext []byte, start, end int) {
+	for i := start; i < end; i++ {
+		prevBlock := c.iv[:]
+		if i > 0 {
+			prevBlock = ciphertext[(i-1)*16 : i*16]
+		}
+
+		block := out[i*16 : (i+1)*16]
+		xorBytes(block, block, prevBlock)
+	}
+}
+
+// NewCTRMode returns a Mode that encrypts successive values of a counter,
+// starting at the given block, and XORs the result with the message.
+// See NIST SP 800-38A Section 6.5.
+func NewCTRMode(cipher Cipher, counter Block) Mode {
+	return &ctr{
+		counter: counter,
+		cipher:  cipher,
 	}
+}
+
+type ctr struct {
+	counter Block
+	cipher  Cipher
+}
 
+// Encrypt turns the block cipher into a stream cipher,
+// so the message does not have to be padded.
+func (c *ctr) Encrypt(bytes []byte) []byte {
+	out := make([]byte, len(bytes))
+	c.xorKeyStream(out, bytes, c.counter)
 	return out
 }
 
-func NewCTRMode(cipher Cipher) Mode {
-	return &ctr{
+func (c *ctr) Decrypt(bytes []byte) []byte {
+	return c.Encrypt(bytes)
+}
+
+// xorKeyStream writes all counter blocks, starting at counter, into a buffer,
+// encrypts the buffer in one batch, and XORs it with src into dst.
+func (c *ctr) xorKeyStream(dst, src []byte, counter Block) {
+	keystream := make([]byte, (len(src)+15)/16*16)
+	for i := 0; i < len(keystream); i += 16 {
+		copy(keystream[i:], counter[:])
+		counter = increment(counter)
+	}
+
+	EncryptBlocks(c.cipher, keystream, keystream)
+	xorBytes(dst, src, keystream)
+}
+
+// increment adds one to a block, treating it as a big-endian 128-bit integer.
+// See NIST SP 800-38A Appendix B.1.
+func increment(b Block) Block {
+	for i := len(b) - 1; i >= 0; i-- {
+		b[i]++
+		if b[i] != 0 {
+			break
+		}
+	}
+
+	return b
+}
+
+// NewOFBMode returns a Mode that repeatedly encrypts the IV
+// and XORs the result with the message.
+// See NIST SP 800-38A Section 6.4.
+func NewOFBMode(cipher Cipher, iv Block) Mode {
+	return &ofb{
+		iv:     iv,
 		cipher: cipher,
 	}
 }
 
-type ctr struct {
-	nonce  int
+type ofb struct {
+	iv     Block
 	cipher Cipher
 }
 
-func (c *ctr) Encrypt(bytes []byte) []byte {
-	blocks := Blockify(bytes, 16)
-	var out []byte
-	for _, b := range blocks {
-		// i = 0
-		nonce := make([]byte, 0, 16)
-		// create byte array with nonce value
-		nonce = append(nonce, LittleEndian(0, 8)...)
-		// nonce = append(nonce, LittleEndian(uint64(c.nonce), 8)...)
-		nonce = append(nonce, LittleEndian(uint64(0), 8)...)
-
-		// encrypt nonce array with cipher to get keystream
-		keystream := c.cipher.Encrypt(Block(nonce))
-
-		// xor keystream with plaintext block to get ciphertext
-		encrypted := make([]byte, 16)
-		for i := 0; i < 16; i++ {
-			encrypted[i] = keystream[i] ^ b[i]
+func (o *ofb) Encrypt(bytes []byte) []byte {
+	out := make([]byte, len(bytes))
+	keystream := o.iv
+
+	for i := 0; i < len(bytes); i += 16 {
+		EncryptBlocks(o.cipher, keystream[:], keystream[:])
+		xorBytes(out[i:], bytes[i:], keystream[:])
+	}
+
+	return out
+}
+
+func (o *ofb) Decrypt(bytes []byte) []byte {
+	return o.Encrypt(bytes)
+}
+
+// NewCFBMode returns a Mode that encrypts a shift register, starting with the IV,
+// and XORs the result with segmentSize bytes of the message at a time.
+// The resulting ciphertext is shifted into the register after every segment.
+// Common segment sizes are 16 (CFB128) and 1 (CFB8).
+// See NIST SP 800-38A Section 6.3.
+func NewCFBMode(cipher Cipher, iv Block, segmentSize int) Mode {
+	if segmentSize < 1 || segmentSize > 16 {
+		panic(fmt.Sprintf("NewCFBMode: segment size must be between 1 and 16 bytes; received %d", segmentSize))
+	}
+
+	return &cfb{
+		iv:          iv,
+		segmentSize: segmentSize,
+		cipher:      cipher,
+	}
+}
+
+type cfb struct {
+	iv          Block
+	segmentSize int
+	cipher      Cipher
+}
+
+func (c *cfb) Encrypt(bytes []byte) []byte {
+	return c.do(bytes, true)
+}
+
+func (c *cfb) Decrypt(bytes []byte) []byte {
+	return c.do(bytes, false)
+}
+
+func (c *cfb) do(bytes []byte, encrypt bool) []byte {
+	out := make([]byte, len(bytes))
+	register := c.iv
+	var keystream Block
+
+	for i := 0; i < len(bytes); i += c.segmentSize {
+		end := i + c.segmentSize
+		if end > len(bytes) {
+			end = len(bytes)
 		}
 
-		out = append(out, encrypted...)
-		c.nonce++
+		EncryptBlocks(c.cipher, keystream[:], register[:])
+		xorBytes(out[i:end], bytes[i:end], keystream[:])
+
+		// The register is always fed with ciphertext,
+		// which is the output when encrypting and the input when decrypting.
+		ciphertext := out[i:end]
+		if !encrypt {
+			ciphertext = bytes[i:end]
+		}
+
+		copy(register[:], register[len(ciphertext):])
+		copy(register[16-len(ciphertext):], ciphertext)
 	}
 
 	return out
 }
 
-func (c *ctr) Decrypt(bytes []byte) []byte {
-	return c.Encrypt(bytes)
+// xorBytes XORs a and b into dst, up to the length of the shortest of the three.
+// dst may be a or b.
+func xorBytes(dst, a, b []byte) {
+	n := len(dst)
+	if len(a) < n {
+		n = len(a)
+	}
+	if len(b) < n {
+		n = len(b)
+	}
+
+	for i := 0; i < n; i++ {
+		dst[i] = a[i] ^ b[i]
+	}
 }
 
 // XOR repeatedly XORs the bytes of key with the bytes of message.
@@ -137,6 +264,9 @@ func XOR(a, b []byte) []byte {
 	return out
 }
 
+// ErrInvalidPadding is returned by UnpadBytes when a message is not padded correctly.
+var ErrInvalidPadding = errors.New("blockcipher: invalid padding")
+
 func PadBytes(bytes []byte, length int) []byte {
 	pad := byte(length - len(bytes))
 	rounds := length - len(bytes)
@@ -147,26 +277,52 @@ func PadBytes(bytes []byte, length int) []byte {
 	return bytes
 }
 
-func Blockify(bytes []byte, size int) []Block {
-	if len(bytes)%size > 0 {
-		bytes = PadBytes(bytes, len(bytes)/size+1)
+// UnpadBytes removes the padding that PadBytes adds when it pads a message
+// to the next multiple of size, where every padding byte holds the number of
+// padding bytes. This is the padding scheme from PKCS #7, see RFC 5652 Section 6.3.
+func UnpadBytes(bytes []byte, size int) ([]byte, error) {
+	if len(bytes) == 0 || len(bytes)%size != 0 {
+		return nil, ErrInvalidPadding
+	}
+
+	pad := int(bytes[len(bytes)-1])
+	if pad == 0 || pad > size {
+		return nil, ErrInvalidPadding
 	}
 
-	block := make([]byte, size)
-	var out []Block
-	for i := range bytes {
-		if i%16 == 0 && i > 0 {
-			out = append(out, Block(block))
-			block = make([]byte, size)
+	for _, b := range bytes[len(bytes)-pad:] {
+		if int(b) != pad {
+			return nil, ErrInvalidPadding
 		}
+	}
+
+	return bytes[:len(bytes)-pad], nil
+}
+
+// Blockify splits bytes into blocks. If the length of bytes is not a multiple
+// of size, the last block is padded with PadBytes first.
+func Blockify(bytes []byte, size int) []Block {
+	bytes = padToBlocks(bytes, size)
 
-		block[i%16] = bytes[i]
+	out := make([]Block, 0, len(bytes)/size)
+	for i := 0; i < len(bytes); i += size {
+		out = append(out, NewBlock(bytes[i:i+size]))
 	}
-	out = append(out, Block(block))
 
 	return out
 }
 
+// padToBlocks returns bytes unchanged if its length is a multiple of size,
+// and a padded copy otherwise, so that padding never writes into the caller's
+// backing array.
+func padToBlocks(bytes []byte, size int) []byte {
+	if len(bytes)%size == 0 {
+		return bytes
+	}
+
+	return PadBytes(append([]byte{}, bytes...), (len(bytes)/size+1)*size)
+}
+
 func LittleEndian(i uint64, wordLen int) []byte {
 	bs := make([]byte, wordLen)
 	binary.LittleEndian.PutUint64(bs, i)
@@ -0,0 +1,176 @@
+package blockcipher
+
+import (
+	"runtime"
+	"sync"
+)
+
+// Parallelism configures how the parallel modes split their input across goroutines.
+// The zero value uses one worker per CPU and DefaultChunkBlocks blocks per chunk.
+//
+// Only modes where every block can be processed independently of the previous
+// output can run in parallel: ECB, CTR and CBC decryption.
+// The output is always identical to that of the serial modes.
+type Parallelism struct {
+	// Workers is the number of goroutines that process chunks.
+	// Defaults to runtime.GOMAXPROCS(0).
+	Workers int
+
+	// ChunkBlocks is the number of consecutive blocks a worker processes at a time.
+	// Defaults to DefaultChunkBlocks.
+	ChunkBlocks int
+}
+
+// DefaultChunkBlocks is the number of blocks per chunk if Parallelism.ChunkBlocks is not set.
+const DefaultChunkBlocks = 64
+
+// forEachChunk splits the blocks in [0, numBlocks) into chunks and calls fn for
+// each of them from a pool of workers. It returns once all chunks are done.
+// Since every chunk covers a separate range, fn can write its results straight
+// into a preallocated output without further synchronisation.
+func (p Parallelism) forEachChunk(numBlocks int, fn func(start, end int)) {
+	workers := p.Workers
+	if workers <= 0 {
+		workers = runtime.GOMAXPROCS(0)
+	}
+
+	chunkBlocks := p.ChunkBlocks
+	if chunkBlocks <= 0 {
+		chunkBlocks = DefaultChunkBlocks
+	}
+
+	chunks := make(chan int)
+	var wg sync.WaitGroup
+	for i := 0; i < workers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for start := range chunks {
+				end := start + chunkBlocks
+				if end > numBlocks {
+					end = numBlocks
+				}
+
+				fn(start, end)
+			}
+		}()
+	}
+
+	for start := 0; start < numBlocks; start += chunkBlocks {
+		chunks <- start
+	}
+	close(chunks)
+
+	wg.Wait()
+}
+
+// NewParallelECBMode returns a Mode that behaves like NewECBMode,
+// but encrypts and decrypts blocks concurrently.
+// The cipher must be safe for concurrent use.
+func NewParallelECBMode(cipher Cipher, p Parallelism) Mode {
+	return &parallelECB{
+		cipher:      cipher,
+		parallelism: p,
+	}
+}
+
+type parallelECB struct {
+	cipher      Cipher
+	parallelism Parallelism
+}
+
+func (e *parallelECB) Encrypt(bytes []byte) []byte {
+	return e.do(EncryptBlocks, bytes)
+}
+
+func (e *parallelECB) Decrypt(bytes []byte) []byte {
+	return e.do(DecryptBlocks, bytes)
+}
+
+func (e *parallelECB) do(crypt func(Cipher, []byte, []byte), bytes []byte) []byte {
+	padded := padToBlocks(bytes, 16)
+	out := make([]byte, len(padded))
+
+	e.parallelism.forEachChunk(len(padded)/16, func(start, end int) {
+		crypt(e.cipher, out[start*16:end*16], padded[start*16:end*16])
+	})
+
+	return out
+}
+
+// NewParallelCBCMode returns a Mode that behaves like NewCBCMode.
+// Encryption is inherently serial, since every block depends on the previous
+// ciphertext, but decryption only depends on ciphertext that is known upfront,
+// so it runs concurrently. The cipher must be safe for concurrent use.
+func NewParallelCBCMode(cipher Cipher, iv Block, p Parallelism) Mode {
+	return &parallelCBC{
+		cbc:         cbc{iv: iv, cipher: cipher},
+		parallelism: p,
+	}
+}
+
+type parallelCBC struct {
+	cbc
+	parallelism Parallelism
+}
+
+func (c *parallelCBC) Decrypt(bytes []byte) []byte {
+	padded := padToBlocks(bytes, 16)
+	out := make([]byte, len(padded))
+
+	c.parallelism.forEachChunk(len(padded)/16, func(start, end int) {
+		DecryptBlocks(c.cipher, out[start*16:end*16], padded[start*16:end*16])
+		c.unchain(out, padded, start, end)
+	})
+
+	return out
+}
+
+// NewParallelCTRMode returns a Mode that behaves like NewCTRMode,
+// but computes the keystream for separate chunks concurrently.
+// The cipher must be safe for concurrent use.
+func NewParallelCTRMode(cipher Cipher, counter Block, p Parallelism) Mode {
+	return &parallelCTR{
+		ctr:         ctr{counter: counter, cipher: cipher},
+		parallelism: p,
+	}
+}
+
+type parallelCTR struct {
+	ctr
+	parallelism Parallelism
+}
+
+func (c *parallelCTR) Encrypt(bytes []byte) []byte {
+	out := make([]byte, len(bytes))
+	numBlocks := (len(bytes) + 15) / 16
+
+	c.parallelism.forEachChunk(numBlocks, func(start, end int) {
+		from, to := start*16, end*16
+		if to > len(bytes) {
+			to = len(bytes)
+		}
+
+		c.xorKeyStream(out[from:to], bytes[from:to], add(c.counter, uint64(start)))
+	})
+
+	return out
+}
+
+func (c *parallelCTR) Decrypt(bytes []byte) []byte {
+	return c.Encrypt(bytes)
+}
+
+// add adds n to a block, treating it as a big-endian 128-bit integer,
+// so that a worker can skip ahead to the counter of its first block.
+func add(b Block, n uint64) Block {
+	var carry uint64
+	for i := len(b) - 1; i >= 0; i-- {
+		sum := uint64(b[i]) + n&0xff + carry
+		b[i] = byte(sum)
+		carry = sum >> 8
+		n >>= 8
+	}
+
+	return b
+}
@@ -0,0 +1,60 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"log"
+
+	"github.com/intersesh/crypto/avalanche"
+)
+
+// measureAvalanche prints how the state changes after every round when a bit
+// of the plaintext or key is flipped, and optionally writes the measurements as CSV.
+//
+//	aes avalanche [-input plaintext|key] [-key-size 16] [-rounds n] [-samples n]
+//	              [-seed n] [-heatmap] [-csv sac.csv] [-distances distances.csv]
+func measureAvalanche(w io.Writer, args []string) {
+	flags := flag.NewFlagSet("avalanche", flag.ExitOnError)
+	input := flags.String("input", "plaintext", "which bits to flip: plaintext or key")
+	keySize := flags.Int("key-size", 16, "key size in bytes: 16, 24 or 32")
+	rounds := flags.Int("rounds", 0, "number of rounds, or 0 for the standard number")
+	samples := flags.Int("samples", 1000, "number of random plaintexts and keys")
+	seed := flags.Int64("seed", 1, "seed for the random samples")
+	heatmap := flags.Bool("heatmap", false, "print a byte-level SAC heatmap for every round")
+	sacPath := flags.String("csv", "", "write the SAC matrix of every round to this CSV file")
+	distancesPath := flags.String("distances", "", "write the Hamming distance distributions to this CSV file")
+	_ = flags.Parse(args)
+
+	cfg := avalanche.Config{KeySize: *keySize, Rounds: *rounds, Samples: *samples, Seed: *seed}
+	switch *input {
+	case "plaintext":
+		cfg.Input = avalanche.Plaintext
+	case "key":
+		cfg.Input = avalanche.Key
+	default:
+		log.Fatal("invalid input: ", *input)
+	}
+
+	if !validKeySize(cfg.KeySize) {
+		log.Fatal("invalid key size: ", cfg.KeySize)
+	}
+	if cfg.Rounds < 0 || cfg.Samples < 1 {
+		log.Fatal("rounds must not be negative, and there must be at least 1 sample")
+	}
+
+	r := avalanche.Measure(cfg)
+
+	out := r.Summary()
+	if *heatmap {
+		for _, round := range r.Rounds {
+			out += fmt.Sprintf("\nround %d\n%s", round.Round, round.Heatmap())
+		}
+	}
+	if _, err := io.WriteString(w, out); err != nil {
+		log.Fatal("failed to write summary: ", err)
+	}
+
+	writeCSV(*sacPath, r.WriteCSV)
+	writeCSV(*distancesPath, r.WriteDistancesCSV)
+}
@@ -0,0 +1,91 @@
+package main
+
+import (
+	stdaes "crypto/aes"
+	stdcipher "crypto/cipher"
+	"fmt"
+	"io"
+	"log"
+	"testing"
+	"text/tabwriter"
+
+	"github.com/intersesh/crypto/aes"
+	"github.com/intersesh/crypto/blockcipher"
+)
+
+// backend is an AES implementation that can be benchmarked.
+type backend struct {
+	name string
+	new  func(key []byte) blockcipher.Cipher
+}
+
+// backends lists every AES implementation available to the bench command.
+// This repository only has the readable implementation in the aes package;
+// the standard library is included as a point of reference, since it uses
+// hardware acceleration where the CPU supports it.
+var backends = []backend{
+	{"matrix", func(key []byte) blockcipher.Cipher {
+		return aes.NewCipher(aes.NewKey(key))
+	}},
+	{"matrix (equivalent inverse)", func(key []byte) blockcipher.Cipher {
+		return aes.NewCipher(aes.NewKey(key), aes.WithEquivalentInverse())
+	}},
+	{"crypto/aes", func(key []byte) blockcipher.Cipher {
+		c, err := stdaes.NewCipher(key)
+		if err != nil {
+			log.Fatal(err)
+		}
+
+		return stdCipher{c}
+	}},
+}
+
+// stdCipher adapts the standard library's AES to blockcipher.Cipher.
+type stdCipher struct {
+	c stdcipher.Block
+}
+
+func (s stdCipher) Encrypt(b blockcipher.Block) blockcipher.Block {
+	var out blockcipher.Block
+	s.c.Encrypt(out[:], b[:])
+	return out
+}
+
+func (s stdCipher) Decrypt(b blockcipher.Block) blockcipher.Block {
+	var out blockcipher.Block
+	s.c.Decrypt(out[:], b[:])
+	return out
+}
+
+// bench measures the single block throughput of every backend and key size,
+// and writes the results to w as a table.
+func bench(w io.Writer) {
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', tabwriter.AlignRight)
+	fmt.Fprintln(tw, "backend\tkey size\tencrypt (MB/s)\tdecrypt (MB/s)\t")
+
+	for _, b := range backends {
+		for _, size := range []int{16, 24, 32} {
+			c := b.new(blockcipher.RandomBytes(size))
+
+			fmt.Fprintf(tw, "%s\t%d\t%.2f\t%.2f\t\n", b.name, size*8, throughput(c.Encrypt), throughput(c.Decrypt))
+		}
+	}
+
+	if err := tw.Flush(); err != nil {
+		log.Fatal(err)
+	}
+}
+
+// throughput returns how many megabytes per second op processes.
+func throughput(op func(blockcipher.Block) blockcipher.Block) float64 {
+	block := blockcipher.NewBlock(blockcipher.RandomBytes(16))
+
+	r := testing.Benchmark(func(b *testing.B) {
+		b.SetBytes(int64(len(block)))
+		for i := 0; i < b.N; i++ {
+			block = op(block)
+		}
+	})
+
+	return float64(r.Bytes) * float64(r.N) / r.T.Seconds() / 1e6
+}
@@ -1,6 +1,7 @@
 package main
 
 import (
+	"encoding/hex"
 	"flag"
 	"io"
 	"log"
@@ -13,6 +14,30 @@ import (
 func main() {
 	flag.Parse()
 
+	switch a := flag.Arg(0); a {
+	case "encrypt", "decrypt":
+		crypt(a)
+	case "bench":
+		bench(os.Stdout)
+	case "whitebox":
+		generateWhitebox(os.Stdout)
+	case "whitebox-encrypt":
+		encryptWhitebox(flag.Arg(1))
+	case "sbox":
+		analyzeSBox(os.Stdout, flag.Args()[1:])
+	case "avalanche":
+		measureAvalanche(os.Stdout, flag.Args()[1:])
+	case "visualize":
+		visualize(flag.Args()[1:])
+	case "report":
+		writeReport(flag.Args()[1:])
+	default:
+		log.Fatal("invalid op: ", a)
+	}
+}
+
+// crypt encrypts or decrypts stdin to stdout, with the key in $AES_KEY.
+func crypt(a string) {
 	// Make sure the key you use is always 16 bytes long.
 	keyStr := os.Getenv("AES_KEY")
 
@@ -23,13 +48,11 @@ func main() {
 		op func(block blockcipher.Block) blockcipher.Block
 	)
 
-	switch a := flag.Arg(0); {
-	case a == "encrypt":
+	switch a {
+	case "encrypt":
 		op = cipher.Encrypt
-	case a == "decrypt":
+	case "decrypt":
 		op = cipher.Decrypt
-	default:
-		log.Fatal("invalid op: ", a)
 	}
 
 	in, err := io.ReadAll(os.Stdin)
@@ -54,3 +77,48 @@ func main() {
 	}
 
 }
+
+// writeCSV calls write with a file created at path, unless path is empty.
+func writeCSV(path string, write func(io.Writer) error) {
+	if path == "" {
+		return
+	}
+
+	f, err := os.Create(path)
+	if err != nil {
+		log.Fatal("failed to create CSV file: ", err)
+	}
+
+	if err := write(f); err != nil {
+		log.Fatal("failed to write CSV file: ", err)
+	}
+
+	if err := f.Close(); err != nil {
+		log.Fatal("failed to write CSV file: ", err)
+	}
+}
+
+// parseKey decodes a key in hexadecimal, and exits if it is not 16, 24 or 32 bytes long.
+func parseKey(s string) aes.Key {
+	key, err := hex.DecodeString(s)
+	if err != nil || !validKeySize(len(key)) {
+		log.Fatal("key must be 16, 24 or 32 bytes in hexadecimal")
+	}
+
+	return aes.NewKey(key)
+}
+
+// validKeySize reports whether n is the size of an AES key in bytes.
+func validKeySize(n int) bool {
+	return n == 16 || n == 24 || n == 32
+}
+
+// parseBlock decodes a block in hexadecimal, and exits if it is not 16 bytes long.
+func parseBlock(s string) blockcipher.Block {
+	block, err := hex.DecodeString(s)
+	if err != nil || len(block) != 16 {
+		log.Fatal("input must be 16 bytes in hexadecimal")
+	}
+
+	return blockcipher.NewBlock(block)
+}
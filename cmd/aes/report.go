@@ -0,0 +1,43 @@
+package main
+
+import (
+	"flag"
+	"log"
+	"os"
+
+	"github.com/intersesh/crypto/walkthrough"
+)
+
+// writeReport writes a self-contained HTML page that walks through one encryption.
+// The key and plaintext default to the example in FIPS-197 Appendix B.
+//
+//	aes report [-key hex] [-input hex] [-o report.html]
+func writeReport(args []string) {
+	flags := flag.NewFlagSet("report", flag.ExitOnError)
+	keyHex := flags.String("key", "2b7e151628aed2a6abf7158809cf4f3c", "key in hexadecimal, 16, 24 or 32 bytes")
+	inputHex := flags.String("input", "3243f6a8885a308d313198a2e0370734", "plaintext block in hexadecimal")
+	path := flags.String("o", "", "write the page to this file instead of stdout")
+	_ = flags.Parse(args)
+
+	report := walkthrough.NewReport(parseKey(*keyHex), parseBlock(*inputHex))
+
+	if *path == "" {
+		if err := report.WriteHTML(os.Stdout); err != nil {
+			log.Fatal("failed to write report: ", err)
+		}
+		return
+	}
+
+	f, err := os.Create(*path)
+	if err != nil {
+		log.Fatal("failed to create report: ", err)
+	}
+
+	if err := report.WriteHTML(f); err != nil {
+		log.Fatal("failed to write report: ", err)
+	}
+
+	if err := f.Close(); err != nil {
+		log.Fatal("failed to write report: ", err)
+	}
+}
@@ -0,0 +1,42 @@
+package main
+
+import (
+	"flag"
+	"io"
+	"log"
+	"os"
+
+	"github.com/intersesh/crypto/sbox"
+)
+
+// analyzeSBox prints the properties of the AES S-box, or of the S-box in the
+// file given as argument, and optionally writes its DDT and LAT as CSV.
+//
+//	aes sbox [-ddt ddt.csv] [-lat lat.csv] [file]
+func analyzeSBox(w io.Writer, args []string) {
+	flags := flag.NewFlagSet("sbox", flag.ExitOnError)
+	ddtPath := flags.String("ddt", "", "write the difference distribution table to this CSV file")
+	latPath := flags.String("lat", "", "write the linear approximation table to this CSV file")
+	_ = flags.Parse(args)
+
+	s := sbox.AES
+	if path := flags.Arg(0); path != "" {
+		f, err := os.Open(path)
+		if err != nil {
+			log.Fatal("failed to open S-box: ", err)
+		}
+		defer f.Close()
+
+		if s, err = sbox.Parse(f); err != nil {
+			log.Fatal("failed to read S-box: ", err)
+		}
+	}
+
+	if _, err := io.WriteString(w, s.Analyze().String()); err != nil {
+		log.Fatal("failed to write report: ", err)
+	}
+
+	// The tables are only computed if they are written.
+	writeCSV(*ddtPath, func(w io.Writer) error { return s.DDT().WriteCSV(w) })
+	writeCSV(*latPath, func(w io.Writer) error { return s.LAT().WriteCSV(w) })
+}
@@ -0,0 +1,120 @@
+package main
+
+import (
+	"bufio"
+	"flag"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"strings"
+
+	"github.com/intersesh/crypto/walkthrough"
+	"golang.org/x/term"
+)
+
+// visualize steps through an encryption or decryption one operation at a time.
+// The key and input default to the example in FIPS-197 Appendix B,
+// so the input is its ciphertext when decrypting.
+// If stdin is not a terminal, every operation is printed in order instead.
+//
+//	aes visualize [-decrypt] [-key hex] [-input hex] [-no-color]
+func visualize(args []string) {
+	flags := flag.NewFlagSet("visualize", flag.ExitOnError)
+	decrypt := flags.Bool("decrypt", false, "step through Decrypt instead of Encrypt")
+	keyHex := flags.String("key", "2b7e151628aed2a6abf7158809cf4f3c", "key in hexadecimal, 16, 24 or 32 bytes")
+	inputHex := flags.String("input", "", "input block in hexadecimal (default 3243f6a8885a308d313198a2e0370734,\nor 3925841d02dc09fbdc118597196a0b32 with -decrypt)")
+	noColor := flags.Bool("no-color", false, "mark changed bytes with '*' instead of reverse video")
+	_ = flags.Parse(args)
+
+	if *inputHex == "" {
+		*inputHex = "3243f6a8885a308d313198a2e0370734"
+		if *decrypt {
+			*inputHex = "3925841d02dc09fbdc118597196a0b32"
+		}
+	}
+
+	operations := walkthrough.Encryption
+	if *decrypt {
+		operations = walkthrough.Decryption
+	}
+	ops := operations(parseKey(*keyHex), parseBlock(*inputHex))
+
+	fd := int(os.Stdin.Fd())
+	if !term.IsTerminal(fd) || !term.IsTerminal(int(os.Stdout.Fd())) {
+		for i, op := range ops {
+			fmt.Printf("step %d of %d, %s\n", i+1, len(ops), op.Render(false))
+		}
+		return
+	}
+
+	state, err := term.MakeRaw(fd)
+	if err != nil {
+		log.Fatal("failed to switch the terminal to raw mode: ", err)
+	}
+	defer term.Restore(fd, state)
+
+	runVisualizer(os.Stdout, bufio.NewReader(os.Stdin), ops, !*noColor)
+}
+
+// runVisualizer draws one operation at a time and moves between them on key presses.
+func runVisualizer(w io.Writer, r *bufio.Reader, ops []walkthrough.Operation, color bool) {
+	const help = "→/n/space next   ←/p previous   g first   G last   q quit"
+
+	current := 0
+	for {
+		screen := fmt.Sprintf("step %d of %d, %s\n%s\n", current+1, len(ops), ops[current].Render(color), help)
+
+		// The terminal is in raw mode, so lines need an explicit carriage return.
+		// \x1b[H\x1b[2J moves the cursor home and clears the screen.
+		fmt.Fprint(w, "\x1b[H\x1b[2J"+strings.ReplaceAll(screen, "\n", "\r\n"))
+
+		next, quit := readKey(r, current, len(ops))
+		if quit {
+			return
+		}
+		current = next
+	}
+}
+
+// readKey reads one key press and returns the index of the operation to show next.
+func readKey(r *bufio.Reader, current, n int) (next int, quit bool) {
+	b, err := r.ReadByte()
+	if err != nil {
+		return current, true
+	}
+
+	switch b {
+	case 'q', 3, 4: // Ctrl-C and Ctrl-D, which raw mode no longer turns into signals.
+		return current, true
+	case 'n', 'l', ' ', '\r':
+		current++
+	case 'p', 'h', 127:
+		current--
+	case 'g':
+		current = 0
+	case 'G':
+		current = n - 1
+	case 0x1b:
+		// Arrow keys are sent as ESC [ C and ESC [ D.
+		if seq, err := r.Peek(2); err == nil && seq[0] == '[' {
+			arrow := seq[1]
+			_, _ = r.Discard(2)
+			switch arrow {
+			case 'C':
+				current++
+			case 'D':
+				current--
+			}
+		}
+	}
+
+	if current < 0 {
+		current = 0
+	}
+	if current >= n {
+		current = n - 1
+	}
+
+	return current, false
+}
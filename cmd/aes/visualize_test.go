@@ -0,0 +1,101 @@
+package main
+
+import (
+	"bufio"
+	"encoding/hex"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/intersesh/crypto/aes"
+	"github.com/intersesh/crypto/blockcipher"
+	"github.com/intersesh/crypto/walkthrough"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestReadKey(t *testing.T) {
+	const n = 5
+
+	for _, test := range []struct {
+		name  string
+		keys  string
+		start int
+		want  int
+		quit  bool
+	}{
+		{name: "n", keys: "n", want: 1},
+		{name: "l", keys: "l", want: 1},
+		{name: "space", keys: " ", want: 1},
+		{name: "enter", keys: "\r", want: 1},
+		{name: "right arrow", keys: "\x1b[C", want: 1},
+		{name: "p", keys: "p", start: 2, want: 1},
+		{name: "h", keys: "h", start: 2, want: 1},
+		{name: "backspace", keys: "\x7f", start: 2, want: 1},
+		{name: "left arrow", keys: "\x1b[D", start: 2, want: 1},
+		{name: "g", keys: "g", start: 3, want: 0},
+		{name: "G", keys: "G", want: n - 1},
+		{name: "arrows", keys: "\x1b[C\x1b[C\x1b[D\x1b[C", want: 2},
+		{name: "clamped at the start", keys: "pp\x1b[D", want: 0},
+		{name: "clamped at the end", keys: "nnnnnnn\x1b[C", want: n - 1},
+		{name: "G then back", keys: "Gp", want: n - 2},
+		{name: "unknown key", keys: "x", start: 2, want: 2},
+		{name: "unknown escape sequence", keys: "\x1b[A", start: 2, want: 2},
+		{name: "q", keys: "nnq", want: 2, quit: true},
+		{name: "Ctrl-C", keys: "n\x03n", want: 1, quit: true},
+		{name: "Ctrl-D", keys: "\x04", quit: true},
+		{name: "end of input", keys: "", start: 1, want: 1, quit: true},
+	} {
+		t.Run(test.name, func(t *testing.T) {
+			current, quit := pressKeys(test.keys, test.start, n)
+			assert.Equal(t, test.want, current)
+			assert.Equal(t, test.quit, quit)
+		})
+	}
+}
+
+// pressKeys calls readKey until it quits or every key has been read.
+func pressKeys(keys string, current, n int) (int, bool) {
+	r := bufio.NewReader(strings.NewReader(keys))
+	for {
+		next, quit := readKey(r, current, n)
+		if quit {
+			return next, true
+		}
+
+		current = next
+		if r.Buffered() == 0 {
+			return current, false
+		}
+	}
+}
+
+func TestRunVisualizer(t *testing.T) {
+	key, err := hex.DecodeString("2b7e151628aed2a6abf7158809cf4f3c")
+	require.NoError(t, err)
+	input, err := hex.DecodeString("3243f6a8885a308d313198a2e0370734")
+	require.NoError(t, err)
+	ops := walkthrough.Encryption(aes.NewKey(key), blockcipher.NewBlock(input))
+
+	for _, test := range []struct {
+		keys  string
+		steps []int
+	}{
+		{keys: "q", steps: []int{1}},
+		{keys: "nn\x1b[Dq", steps: []int{1, 2, 3, 2}},
+		{keys: "pGn\x03", steps: []int{1, 1, len(ops), len(ops)}},
+		{keys: "gn", steps: []int{1, 1, 2}},
+	} {
+		t.Run(fmt.Sprintf("%q", test.keys), func(t *testing.T) {
+			var b strings.Builder
+			runVisualizer(&b, bufio.NewReader(strings.NewReader(test.keys)), ops, false)
+
+			screens := strings.Split(b.String(), "\x1b[H\x1b[2J")[1:]
+			require.Len(t, screens, len(test.steps))
+			for i, step := range test.steps {
+				assert.True(t, strings.HasPrefix(screens[i], fmt.Sprintf("step %d of %d, ", step, len(ops))), screens[i])
+				assert.NotContains(t, strings.ReplaceAll(screens[i], "\r\n", ""), "\n")
+			}
+		})
+	}
+}
@@ -0,0 +1,54 @@
+package main
+
+import (
+	"encoding/binary"
+	"io"
+	"log"
+	"os"
+
+	"github.com/intersesh/crypto/blockcipher"
+	"github.com/intersesh/crypto/whitebox"
+)
+
+// generateWhitebox writes white-box tables for the 16-byte key in $AES_KEY to w.
+func generateWhitebox(w io.Writer) {
+	var key [16]byte
+	keyStr := os.Getenv("AES_KEY")
+	if len(keyStr) != len(key) {
+		log.Fatal("white-box tables need a 16 byte key in AES_KEY")
+	}
+	copy(key[:], keyStr)
+
+	seed := int64(binary.BigEndian.Uint64(blockcipher.RandomBytes(8)))
+	if _, err := whitebox.Generate(key, seed).WriteTo(w); err != nil {
+		log.Fatal("failed to write tables: ", err)
+	}
+}
+
+// encryptWhitebox encrypts stdin to stdout with the tables in the given file,
+// without ever seeing the key. Like the ECB mode, the input is padded to a
+// multiple of the block size.
+func encryptWhitebox(path string) {
+	f, err := os.Open(path)
+	if err != nil {
+		log.Fatal("failed to open tables: ", err)
+	}
+	defer f.Close()
+
+	tables, err := whitebox.ReadTables(f)
+	if err != nil {
+		log.Fatal("failed to read tables: ", err)
+	}
+
+	in, err := io.ReadAll(os.Stdin)
+	if err != nil {
+		log.Fatal("error reading from stdin: ", err)
+	}
+
+	for _, block := range blockcipher.Blockify(in, 16) {
+		b := tables.Encrypt(block)
+		if _, err := os.Stdout.Write(b[:]); err != nil {
+			log.Fatal("failed to write to stdout: ", err)
+		}
+	}
+}
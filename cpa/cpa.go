@@ -0,0 +1,203 @@
+// Package cpa implements correlation power analysis against the first round of
+// AES, using traces from a simulated device such as aes.LeakageRecorder.
+// See E. Brier, C. Clavier and F. Olivier, 'Correlation Power Analysis with a
+// Leakage Model', CHES 2004.
+package cpa
+
+import (
+	"fmt"
+	"math"
+	"math/bits"
+	"sort"
+
+	"github.com/intersesh/crypto/aes"
+	"github.com/intersesh/crypto/blockcipher"
+)
+
+// Source returns the power trace of a device encrypting the given plaintext.
+type Source func(plaintext blockcipher.Block) []float64
+
+// SimulatedSource returns a Source that encrypts with the cipher in the aes
+// package and records its leakage with an aes.LeakageRecorder.
+func SimulatedSource(key aes.Key, noise float64, seed int64) Source {
+	recorder := aes.NewLeakageRecorder(noise, seed)
+	return simulate(aes.NewCipher(key, aes.WithLeakage(recorder)), recorder)
+}
+
+// SimulatedMaskedSource is like SimulatedSource, but uses aes.MaskedCipher.
+// The first-order attack in this package is not expected to work against it.
+func SimulatedMaskedSource(key aes.Key, noise float64, seed int64) Source {
+	recorder := aes.NewLeakageRecorder(noise, seed)
+	return simulate(aes.NewMaskedCipher(key, aes.WithLeakage(recorder)), recorder)
+}
+
+func simulate(c blockcipher.Cipher, recorder *aes.LeakageRecorder) Source {
+	return func(plaintext blockcipher.Block) []float64 {
+		recorder.Reset()
+		c.Encrypt(plaintext)
+		return recorder.Traces()[0]
+	}
+}
+
+// Result holds, for every byte of the key and every guess for it,
+// the highest absolute correlation between the predicted leakage and any
+// sample of the traces.
+type Result struct {
+	Correlations [16][256]float64
+}
+
+// Key returns the guess with the highest correlation for every byte.
+func (r Result) Key() aes.Key {
+	var key blockcipher.Block
+	for i := range key {
+		key[i] = byte(r.ranking(i)[0])
+	}
+
+	return aes.NewKey(key[:])
+}
+
+// Rank returns the position of the correct byte among all guesses for the
+// byte at index i, ordered by correlation. A rank of 0 means the attack found it.
+func (r Result) Rank(i int, correct byte) int {
+	for rank, guess := range r.ranking(i) {
+		if byte(guess) == correct {
+			return rank
+		}
+	}
+
+	panic("unreachable")
+}
+
+func (r Result) ranking(i int) []int {
+	guesses := make([]int, 256)
+	for g := range guesses {
+		guesses[g] = g
+	}
+
+	sort.SliceStable(guesses, func(a, b int) bool {
+		return r.Correlations[i][guesses[a]] > r.Correlations[i][guesses[b]]
+	})
+
+	return guesses
+}
+
+// Attack correlates the Hamming weight of SubBytes(plaintext ^ guess) with
+// every sample of the traces, for every byte of the key and every guess.
+// The correct guess is expected to correlate most strongly with one of the samples.
+// Panics unless there is one trace per plaintext and all traces are of the same length.
+func Attack(plaintexts []blockcipher.Block, traces [][]float64) Result {
+	if len(plaintexts) != len(traces) {
+		panic(fmt.Sprintf("Attack: received %d plaintexts but %d traces", len(plaintexts), len(traces)))
+	}
+
+	var result Result
+	if len(traces) == 0 {
+		return result
+	}
+
+	numSamples := len(traces[0])
+	samples := make([][]float64, numSamples)
+	for s := range samples {
+		samples[s] = make([]float64, len(traces))
+		for t, trace := range traces {
+			if len(trace) != numSamples {
+				panic(fmt.Sprintf("Attack: trace %d has %d samples, expected %d", t, len(trace), numSamples))
+			}
+
+			samples[s][t] = trace[s]
+		}
+	}
+
+	predictions := make([]float64, len(plaintexts))
+	for i := 0; i < 16; i++ {
+		for guess := 0; guess < 256; guess++ {
+			for t, p := range plaintexts {
+				predictions[t] = float64(bits.OnesCount8(sbox[p[i]^byte(guess)]))
+			}
+
+			for _, s := range samples {
+				if c := math.Abs(pearson(predictions, s)); c > result.Correlations[i][guess] {
+					result.Correlations[i][guess] = c
+				}
+			}
+		}
+	}
+
+	return result
+}
+
+// pearson returns the Pearson correlation coefficient of x and y,
+// or 0 if either of them is constant.
+func pearson(x, y []float64) float64 {
+	n := float64(len(x))
+
+	var sumX, sumY, sumXX, sumYY, sumXY float64
+	for i := range x {
+		sumX += x[i]
+		sumY += y[i]
+		sumXX += x[i] * x[i]
+		sumYY += y[i] * y[i]
+		sumXY += x[i] * y[i]
+	}
+
+	denominator := math.Sqrt(n*sumXX-sumX*sumX) * math.Sqrt(n*sumYY-sumY*sumY)
+	if denominator == 0 {
+		return 0
+	}
+
+	return (n*sumXY - sumX*sumY) / denominator
+}
+
+// sbox is looked up for every plaintext byte and key guess.
+var sbox = aes.SBoxTable()
+
+// Point summarises how well the attack does with a given number of traces.
+type Point struct {
+	Traces int
+
+	// SuccessRate is the fraction of experiments that recovered the whole key.
+	SuccessRate float64
+
+	// MeanRank is the average rank of the correct key byte, over all bytes and experiments.
+	MeanRank float64
+}
+
+// Evaluate runs the given number of experiments for every trace count,
+// each with fresh random plaintexts from source, and reports how often the
+// attack recovers key, the key that source is known to use.
+func Evaluate(source Source, key aes.Key, traceCounts []int, experiments int) []Point {
+	correct := key.Bytes()
+
+	var out []Point
+	for _, count := range traceCounts {
+		point := Point{Traces: count}
+
+		for e := 0; e < experiments; e++ {
+			plaintexts := make([]blockcipher.Block, count)
+			traces := make([][]float64, count)
+			for t := range plaintexts {
+				plaintexts[t] = blockcipher.NewBlock(blockcipher.RandomBytes(16))
+				traces[t] = source(plaintexts[t])
+			}
+
+			result := Attack(plaintexts, traces)
+
+			success := true
+			for i := range correct {
+				rank := result.Rank(i, correct[i])
+				point.MeanRank += float64(rank)
+				success = success && rank == 0
+			}
+
+			if success {
+				point.SuccessRate++
+			}
+		}
+
+		point.SuccessRate /= float64(experiments)
+		point.MeanRank /= float64(16 * experiments)
+		out = append(out, point)
+	}
+
+	return out
+}
@@ -0,0 +1,48 @@
+package cpa
+
+import (
+	"testing"
+
+	"github.com/intersesh/crypto/aes"
+	"github.com/intersesh/crypto/blockcipher"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAttack(t *testing.T) {
+	key := aes.NewKey(blockcipher.RandomBytes(16))
+	source := SimulatedSource(key, 1, 1)
+
+	plaintexts := make([]blockcipher.Block, 500)
+	traces := make([][]float64, len(plaintexts))
+	for i := range plaintexts {
+		plaintexts[i] = blockcipher.NewBlock(blockcipher.RandomBytes(16))
+		traces[i] = source(plaintexts[i])
+	}
+
+	result := Attack(plaintexts, traces)
+	assert.Equal(t, key, result.Key())
+	for i, b := range key.Bytes() {
+		assert.Equal(t, 0, result.Rank(i, b))
+	}
+}
+
+// TestEvaluate checks that the success rate grows with the number of traces.
+func TestEvaluate(t *testing.T) {
+	key := aes.NewKey(blockcipher.RandomBytes(16))
+
+	points := Evaluate(SimulatedSource(key, 2, 1), key, []int{5, 1000}, 2)
+	assert.Equal(t, 0.0, points[0].SuccessRate)
+	assert.Greater(t, points[0].MeanRank, 10.0)
+	assert.Equal(t, 1.0, points[1].SuccessRate)
+	assert.Equal(t, 0.0, points[1].MeanRank)
+}
+
+// TestMaskedCipher checks that masking defeats the attack, even without noise
+// and with more traces than it takes to break the unmasked cipher.
+func TestMaskedCipher(t *testing.T) {
+	key := aes.NewKey(blockcipher.RandomBytes(16))
+
+	points := Evaluate(SimulatedMaskedSource(key, 0, 1), key, []int{1000}, 1)
+	assert.Equal(t, 0.0, points[0].SuccessRate)
+	assert.Greater(t, points[0].MeanRank, 50.0)
+}
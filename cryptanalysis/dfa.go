@@ -0,0 +1,137 @@
+package cryptanalysis
+
+import (
+	"github.com/intersesh/crypto/aes"
+	"github.com/intersesh/crypto/blockcipher"
+)
+
+// FaultPair holds the ciphertexts of the same plaintext with and without a fault.
+type FaultPair struct {
+	Correct, Faulty blockcipher.Block
+}
+
+// mixColumnCoefficients is the MixColumns matrix from FIPS-197 Section 5.1.3.
+var mixColumnCoefficients = aes.MixColumnsMatrix()
+
+// PiretQuisquater recovers an AES-128 key from ciphertext pairs where a
+// single byte of the state was faulted between ShiftRows and MixColumns of
+// round 9, e.g. with aes.ByteFault{Round: 9, Step: aes.StepShiftRows}.
+//
+// MixColumns spreads a difference δ in row f of a column into the differences
+// M[r][f]·δ in every row r of that column. The last round has no MixColumns,
+// so each of these four bytes ends up in a ciphertext byte of its own, and a guess
+// for the matching four bytes of the last round key can be checked by undoing
+// AddRoundKey and SubBytes on both ciphertexts. Every pair leaves about 2¹⁰
+// candidates for its four key bytes. Two pairs per column of the state single
+// out the right one most of the time; a third pair settles the rare remainder.
+//
+// Pairs that do not have the difference pattern of such a fault are ignored.
+// Once the last round key is known, the cipher key follows from aes.RecoverKey.
+// See G. Piret and J.-J. Quisquater, 'A Differential Fault Attack Technique
+// against SPN Structures, with Application to the AES and KHAZAD', CHES 2003.
+func PiretQuisquater(pairs []FaultPair) (aes.Key, error) {
+	var candidates [4]map[[4]byte]bool
+
+	for _, p := range pairs {
+		column, ok := faultedColumn(p)
+		if !ok {
+			continue
+		}
+
+		found := columnCandidates(p, column)
+		if candidates[column] != nil {
+			for k := range candidates[column] {
+				if !found[k] {
+					delete(candidates[column], k)
+				}
+			}
+		} else {
+			candidates[column] = found
+		}
+	}
+
+	var roundKey blockcipher.Block
+	for column, c := range candidates {
+		if len(c) != 1 {
+			return nil, ErrNoKey
+		}
+
+		for k := range c {
+			for row, b := range k {
+				roundKey[ciphertextIndex(row, column)] = b
+			}
+		}
+	}
+
+	// The key of round 10 starts at word 4 * 10 of the schedule.
+	return aes.RecoverKey(aes.Words(roundKey[:]), 40), nil
+}
+
+// ciphertextIndex returns where the last round's ShiftRows moves the byte in the
+// given row and column of the state to, as an index into the ciphertext.
+func ciphertextIndex(row, column int) int {
+	return 4*((column-row+4)%4) + row
+}
+
+// faultedColumn returns the column of the state before the last round that a
+// fault in round 9 affected, if the ciphertexts differ in exactly the four bytes
+// that column ends up in.
+func faultedColumn(p FaultPair) (int, bool) {
+	for column := 0; column < 4; column++ {
+		var expected [16]bool
+		for row := 0; row < 4; row++ {
+			expected[ciphertextIndex(row, column)] = true
+		}
+
+		matches := true
+		for i := range p.Correct {
+			if (p.Correct[i] != p.Faulty[i]) != expected[i] {
+				matches = false
+				break
+			}
+		}
+
+		if matches {
+			return column, true
+		}
+	}
+
+	return 0, false
+}
+
+// columnCandidates returns every guess for the four bytes of the last round key
+// that line up with the given column, for which the state before the last round
+// differs by the image of a single byte difference under MixColumns.
+func columnCandidates(p FaultPair, column int) map[[4]byte]bool {
+	// keysByDiff[row][d] lists the key bytes that lead to difference d in that row.
+	var keysByDiff [4][256][]byte
+	for row := 0; row < 4; row++ {
+		i := ciphertextIndex(row, column)
+		for k := 0; k < 256; k++ {
+			d := sboxInverse[p.Correct[i]^byte(k)] ^ sboxInverse[p.Faulty[i]^byte(k)]
+			keysByDiff[row][d] = append(keysByDiff[row][d], byte(k))
+		}
+	}
+
+	out := make(map[[4]byte]bool)
+	for f := 0; f < 4; f++ {
+		for delta := 1; delta < 256; delta++ {
+			var lists [4][]byte
+			for row := range lists {
+				lists[row] = keysByDiff[row][aes.Multiply(mixColumnCoefficients[row][f], byte(delta))]
+			}
+
+			for _, k0 := range lists[0] {
+				for _, k1 := range lists[1] {
+					for _, k2 := range lists[2] {
+						for _, k3 := range lists[3] {
+							out[[4]byte{k0, k1, k2, k3}] = true
+						}
+					}
+				}
+			}
+		}
+	}
+
+	return out
+}
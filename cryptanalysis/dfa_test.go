@@ -0,0 +1,46 @@
+package cryptanalysis
+
+import (
+	"testing"
+
+	"github.com/intersesh/crypto/aes"
+	"github.com/intersesh/crypto/blockcipher"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPiretQuisquater(t *testing.T) {
+	for i := 0; i < 4; i++ {
+		key := aes.NewKey(blockcipher.RandomBytes(16))
+		c := aes.NewCipher(key)
+
+		// Three faults in every column, in random rows and with random nonzero values.
+		var pairs []FaultPair
+		for column := 0; column < 4; column++ {
+			for j := 0; j < 3; j++ {
+				r := blockcipher.RandomBytes(2)
+				fault := aes.ByteFault{
+					Round: 9,
+					Step:  aes.StepShiftRows,
+					Index: 4*column + int(r[0]%4),
+					Mask:  r[1]%255 + 1,
+				}
+
+				plaintext := blockcipher.NewBlock(blockcipher.RandomBytes(16))
+				pairs = append(pairs, FaultPair{
+					Correct: c.Encrypt(plaintext),
+					Faulty:  aes.NewCipher(key, aes.WithFaultInjector(fault)).Encrypt(plaintext),
+				})
+			}
+		}
+
+		recovered, err := PiretQuisquater(pairs)
+		require.NoError(t, err)
+		assert.Equal(t, key, recovered)
+	}
+}
+
+func TestPiretQuisquaterNotEnoughPairs(t *testing.T) {
+	_, err := PiretQuisquater(nil)
+	assert.ErrorIs(t, err, ErrNoKey)
+}
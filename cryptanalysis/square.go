@@ -0,0 +1,110 @@
+// Package cryptanalysis implements attacks on reduced-round variants of the
+// cipher in the aes package. They are meant for teaching and only work
+// because the number of rounds is far below what AES prescribes.
+package cryptanalysis
+
+import (
+	"errors"
+
+	"github.com/intersesh/crypto/aes"
+	"github.com/intersesh/crypto/blockcipher"
+)
+
+// Oracle encrypts chosen plaintexts under a key that the attacker does not know.
+type Oracle func(blockcipher.Block) blockcipher.Block
+
+// ErrNoKey is returned when an attack does not narrow the key down to a single candidate.
+var ErrNoKey = errors.New("cryptanalysis: key not found")
+
+// sboxInverse is looked up for every guess of a key byte.
+var sboxInverse = aes.InvSBoxTable()
+
+// NewLambdaSet returns 256 blocks that are equal to constant, except for the
+// byte at index active, which takes every possible value once.
+// See 'The Design of Rijndael' Section 10.2.1.
+func NewLambdaSet(constant blockcipher.Block, active int) [256]blockcipher.Block {
+	var out [256]blockcipher.Block
+	for i := range out {
+		out[i] = constant
+		out[i][active] = byte(i)
+	}
+
+	return out
+}
+
+// maxLambdaSets bounds the number of Λ-sets SquareAttack asks the oracle for.
+// A wrong guess survives a Λ-set with probability 1/256, so a handful of sets
+// is plenty to leave a single candidate for every byte.
+const maxLambdaSets = 8
+
+// SquareAttack recovers the key of AES-128 reduced to 4 rounds,
+// as constructed by aes.NewCipher with aes.WithRounds(4).
+//
+// After 3 rounds, every byte of the state XORs to zero over a Λ-set, i.e. it
+// is balanced. The fourth and last round has no MixColumns, so every byte of
+// the state before it only depends on one ciphertext byte and one byte of
+// the last round key. For each guess of that key byte, undoing AddRoundKey
+// and SubBytes must give a balanced byte; almost all wrong guesses do not.
+// ShiftRows only moves bytes around and does not affect balance.
+//
+// Once the last round key is known, the cipher key follows from aes.RecoverKey.
+// See 'The Design of Rijndael' Section 10.2.
+func SquareAttack(oracle Oracle) (aes.Key, error) {
+	var candidates [16]map[byte]bool
+	for i := range candidates {
+		candidates[i] = make(map[byte]bool, 256)
+		for k := 0; k < 256; k++ {
+			candidates[i][byte(k)] = true
+		}
+	}
+
+	for set := 0; set < maxLambdaSets && !unique(candidates); set++ {
+		var ciphertexts [256]blockcipher.Block
+		for i, p := range NewLambdaSet(blockcipher.NewBlock(blockcipher.RandomBytes(16)), 0) {
+			ciphertexts[i] = oracle(p)
+		}
+
+		for pos := range candidates {
+			for k := range candidates[pos] {
+				if !balanced(ciphertexts, pos, k) {
+					delete(candidates[pos], k)
+				}
+			}
+		}
+	}
+
+	if !unique(candidates) {
+		return nil, ErrNoKey
+	}
+
+	var roundKey blockcipher.Block
+	for pos, c := range candidates {
+		for k := range c {
+			roundKey[pos] = k
+		}
+	}
+
+	// The key of round 4 starts at word 4 * 4 of the schedule.
+	return aes.RecoverKey(aes.Words(roundKey[:]), 16), nil
+}
+
+// balanced reports whether the byte at pos of the state before the last round
+// XORs to zero over all ciphertexts, assuming k is the byte of the last round key at pos.
+func balanced(ciphertexts [256]blockcipher.Block, pos int, k byte) bool {
+	var sum byte
+	for _, c := range ciphertexts {
+		sum ^= sboxInverse[c[pos]^k]
+	}
+
+	return sum == 0
+}
+
+func unique(candidates [16]map[byte]bool) bool {
+	for _, c := range candidates {
+		if len(c) != 1 {
+			return false
+		}
+	}
+
+	return true
+}
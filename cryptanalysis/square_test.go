@@ -0,0 +1,30 @@
+package cryptanalysis
+
+import (
+	"testing"
+
+	"github.com/intersesh/crypto/aes"
+	"github.com/intersesh/crypto/blockcipher"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSquareAttack(t *testing.T) {
+	for i := 0; i < 4; i++ {
+		key := aes.NewKey(blockcipher.RandomBytes(16))
+		c := aes.NewCipher(key, aes.WithRounds(4))
+
+		recovered, err := SquareAttack(c.Encrypt)
+		require.NoError(t, err)
+		assert.Equal(t, key, recovered)
+	}
+}
+
+// TestSquareAttackFullRounds checks that the attack fails on the full cipher,
+// where the balance property no longer holds in the last round.
+func TestSquareAttackFullRounds(t *testing.T) {
+	c := aes.NewCipher(aes.NewKey(blockcipher.RandomBytes(16)))
+
+	_, err := SquareAttack(c.Encrypt)
+	assert.ErrorIs(t, err, ErrNoKey)
+}
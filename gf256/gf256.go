@@ -0,0 +1,200 @@
+// Package gf256 implements arithmetic in finite fields with 256 elements.
+//
+// Elements are polynomials of degree less than 8 over GF(2), stored as a byte
+// with bit i holding the coefficient of xⁱ. Addition is XOR, and multiplication
+// is polynomial multiplication modulo an irreducible polynomial of degree 8.
+// AES uses x⁸ + x⁴ + x³ + x + 1, see FIPS-197 Section 4.
+package gf256
+
+import (
+	"fmt"
+	"math/bits"
+)
+
+// Element is an element of GF(2⁸).
+type Element byte
+
+// String returns the hexadecimal notation used by FIPS-197, e.g. {57}.
+func (e Element) String() string {
+	return fmt.Sprintf("{%02x}", byte(e))
+}
+
+// Add returns a + b, which is the same as a - b in every field of characteristic 2.
+func Add(a, b Element) Element {
+	return a ^ b
+}
+
+// Field holds a reduction polynomial together with log and antilog tables
+// for one of its generators.
+type Field struct {
+	polynomial uint16
+	generator  Element
+
+	// exp[i] is generatorⁱ, repeated once so that the sum of two logarithms
+	// can be looked up without reducing it mod 255 first.
+	exp [510]Element
+	log [256]int
+}
+
+// AESPolynomial is the reduction polynomial x⁸ + x⁴ + x³ + x + 1 used by AES.
+const AESPolynomial = 0x11b
+
+// AES is the field used by AES, with {03} as generator.
+// {02} does not generate the whole multiplicative group for this polynomial.
+var AES = NewField(AESPolynomial, 0x03)
+
+// NewField returns GF(2⁸) for the given reduction polynomial, with log and
+// antilog tables for the given generator.
+// Panics unless the polynomial is irreducible and of degree 8, and the
+// generator is a primitive element, i.e. its powers cover every nonzero element.
+func NewField(polynomial uint16, generator Element) *Field {
+	if bits.Len16(polynomial) != 9 || !irreducible(uint(polynomial)) {
+		panic(fmt.Sprintf("NewField: %#x is not an irreducible polynomial of degree 8", polynomial))
+	}
+
+	f := &Field{polynomial: polynomial, generator: generator}
+
+	seen := make(map[Element]bool, 255)
+	x := Element(1)
+	for i := 0; i < 255; i++ {
+		if seen[x] {
+			panic(fmt.Sprintf("NewField: %v does not generate GF(2⁸) modulo %#x", generator, polynomial))
+		}
+		seen[x] = true
+
+		f.exp[i], f.exp[i+255] = x, x
+		f.log[x] = i
+		x = f.MulConstantTime(x, generator)
+	}
+
+	return f
+}
+
+// irreducible reports whether a polynomial over GF(2) has no factors of degree 1 to 4,
+// which is enough for a polynomial of degree 8.
+func irreducible(p uint) bool {
+	for d := uint(2); d < 32; d++ {
+		if mod(p, d) == 0 {
+			return false
+		}
+	}
+
+	return true
+}
+
+// mod returns the remainder of polynomial division over GF(2).
+func mod(dividend, divisor uint) uint {
+	for bits.Len(dividend) >= bits.Len(divisor) {
+		dividend ^= divisor << (bits.Len(dividend) - bits.Len(divisor))
+	}
+
+	return dividend
+}
+
+// Polynomial returns the reduction polynomial of the field.
+func (f *Field) Polynomial() uint16 {
+	return f.polynomial
+}
+
+// Generator returns the primitive element that the log tables are based on.
+func (f *Field) Generator() Element {
+	return f.generator
+}
+
+// Add returns a + b.
+func (f *Field) Add(a, b Element) Element {
+	return Add(a, b)
+}
+
+// Mul returns a · b, using the log and antilog tables.
+// Its running time depends on whether a or b is zero.
+// See MulConstantTime.
+func (f *Field) Mul(a, b Element) Element {
+	if a == 0 || b == 0 {
+		return 0
+	}
+
+	return f.exp[f.log[a]+f.log[b]]
+}
+
+// MulConstantTime returns a · b without branches or table lookups that depend
+// on the operands, by repeatedly multiplying a by x and adding it to the result
+// for every bit of b that is set. See xtime in FIPS-197 Section 4.2.1.
+func (f *Field) MulConstantTime(a, b Element) Element {
+	reduction := Element(f.polynomial)
+
+	var out Element
+	for i := 0; i < 8; i++ {
+		// -(b & 1) is 0xff if the lowest bit of b is set, and 0 otherwise.
+		out ^= a & -(b & 1)
+		b >>= 1
+
+		a = a<<1 ^ reduction&-(a>>7)
+	}
+
+	return out
+}
+
+// Inv returns the multiplicative inverse of a. Panics if a is zero.
+func (f *Field) Inv(a Element) Element {
+	if a == 0 {
+		panic("Inv: zero has no inverse")
+	}
+
+	return f.exp[255-f.log[a]]
+}
+
+// Div returns a / b. Panics if b is zero.
+func (f *Field) Div(a, b Element) Element {
+	if b == 0 {
+		panic("Div: division by zero")
+	}
+	if a == 0 {
+		return 0
+	}
+
+	return f.exp[f.log[a]+255-f.log[b]]
+}
+
+// Pow returns aⁿ. Negative exponents are powers of the inverse of a,
+// and 0⁰ is 1. Panics if a is zero and n is negative.
+func (f *Field) Pow(a Element, n int) Element {
+	if a == 0 {
+		switch {
+		case n == 0:
+			return 1
+		case n < 0:
+			panic("Pow: zero has no inverse")
+		default:
+			return 0
+		}
+	}
+
+	// The multiplicative group has order 255, so exponents are taken mod 255.
+	e := (f.log[a] * (n % 255)) % 255
+	if e < 0 {
+		e += 255
+	}
+
+	return f.exp[e]
+}
+
+// Log returns the discrete logarithm of a to the base of the field's generator,
+// between 0 and 254. Panics if a is zero.
+func (f *Field) Log(a Element) int {
+	if a == 0 {
+		panic("Log: zero has no logarithm")
+	}
+
+	return f.log[a]
+}
+
+// Exp returns the generator raised to the power n.
+func (f *Field) Exp(n int) Element {
+	n %= 255
+	if n < 0 {
+		n += 255
+	}
+
+	return f.exp[n]
+}
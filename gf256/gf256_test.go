@@ -0,0 +1,125 @@
+package gf256
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// multiply is a reference implementation: carry-less multiplication
+// followed by polynomial division.
+func multiply(a, b Element, polynomial uint16) Element {
+	var product uint
+	for i := 0; i < 8; i++ {
+		if b>>i&1 == 1 {
+			product ^= uint(a) << i
+		}
+	}
+
+	return Element(mod(product, uint(polynomial)))
+}
+
+var fields = []struct {
+	name  string
+	field *Field
+}{
+	{"AES", AES},
+	// The polynomial used by Reed-Solomon codes such as in QR codes, where {02} is a generator.
+	{"0x11d", NewField(0x11d, 0x02)},
+}
+
+// TestMul checks all 65536 products of every field against the reference.
+func TestMul(t *testing.T) {
+	for _, f := range fields {
+		t.Run(f.name, func(t *testing.T) {
+			for a := 0; a < 256; a++ {
+				for b := 0; b < 256; b++ {
+					want := multiply(Element(a), Element(b), f.field.Polynomial())
+
+					if got := f.field.Mul(Element(a), Element(b)); got != want {
+						t.Fatalf("Mul(%v, %v) = %v, want %v", Element(a), Element(b), got, want)
+					}
+					if got := f.field.MulConstantTime(Element(a), Element(b)); got != want {
+						t.Fatalf("MulConstantTime(%v, %v) = %v, want %v", Element(a), Element(b), got, want)
+					}
+				}
+			}
+		})
+	}
+}
+
+// TestAESExamples uses the examples from FIPS-197 Section 4.
+func TestAESExamples(t *testing.T) {
+	assert.Equal(t, Element(0xd4), Add(0x57, 0x83))
+	assert.Equal(t, Element(0xc1), AES.Mul(0x57, 0x83))
+	assert.Equal(t, Element(0xfe), AES.Mul(0x57, 0x13))
+	assert.Equal(t, "{57}", Element(0x57).String())
+}
+
+func TestDivision(t *testing.T) {
+	for _, f := range fields {
+		t.Run(f.name, func(t *testing.T) {
+			for a := 0; a < 256; a++ {
+				for b := 1; b < 256; b++ {
+					if got := f.field.Div(f.field.Mul(Element(a), Element(b)), Element(b)); got != Element(a) {
+						t.Fatalf("(%v · %v) / %v = %v", Element(a), Element(b), Element(b), got)
+					}
+				}
+
+				if a != 0 {
+					assert.Equal(t, Element(1), f.field.Mul(Element(a), f.field.Inv(Element(a))))
+				}
+			}
+
+			assert.Panics(t, func() { f.field.Inv(0) })
+			assert.Panics(t, func() { f.field.Div(1, 0) })
+		})
+	}
+}
+
+func TestPow(t *testing.T) {
+	for _, f := range fields {
+		t.Run(f.name, func(t *testing.T) {
+			for a := 0; a < 256; a++ {
+				want := Element(1)
+				for n := 0; n < 600; n++ {
+					assert.Equal(t, want, f.field.Pow(Element(a), n), "%v^%d", Element(a), n)
+					want = f.field.Mul(want, Element(a))
+				}
+
+				if a != 0 {
+					assert.Equal(t, f.field.Inv(Element(a)), f.field.Pow(Element(a), -1))
+					assert.Equal(t, f.field.Inv(f.field.Mul(Element(a), Element(a))), f.field.Pow(Element(a), -2))
+				}
+			}
+
+			assert.Equal(t, Element(1), f.field.Pow(0, 0))
+			assert.Panics(t, func() { f.field.Pow(0, -1) })
+		})
+	}
+}
+
+func TestLog(t *testing.T) {
+	for _, f := range fields {
+		t.Run(f.name, func(t *testing.T) {
+			for a := 1; a < 256; a++ {
+				log := f.field.Log(Element(a))
+				assert.Equal(t, Element(a), f.field.Exp(log))
+				assert.Equal(t, Element(a), f.field.Pow(f.field.Generator(), log))
+			}
+
+			assert.Equal(t, Element(1), f.field.Exp(255))
+			assert.Equal(t, f.field.Generator(), f.field.Exp(-254))
+			assert.Panics(t, func() { f.field.Log(0) })
+		})
+	}
+}
+
+func TestNewField(t *testing.T) {
+	// x⁸ + 1 = (x + 1)⁸ is reducible.
+	assert.Panics(t, func() { NewField(0x101, 0x03) })
+	// Polynomials must have degree 8.
+	assert.Panics(t, func() { NewField(0x1b, 0x03) })
+	// {02} only generates a subgroup of order 51 for the AES polynomial.
+	assert.Panics(t, func() { NewField(AESPolynomial, 0x02) })
+}
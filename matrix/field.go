@@ -0,0 +1,201 @@
+package matrix
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/intersesh/crypto/gf256"
+)
+
+// The methods in this file treat the entries of a matrix as elements of the
+// AES field GF(2⁸), see gf256.AES. Addition is XOR, so there are no signs to
+// keep track of, and subtraction is the same as addition.
+
+// ErrSingular is returned by Inverse when a matrix has no inverse.
+var ErrSingular = errors.New("matrix: singular matrix")
+
+var field = gf256.AES
+
+func mul(a, b byte) byte {
+	return byte(field.Mul(gf256.Element(a), gf256.Element(b)))
+}
+
+// Identity returns the n×n identity matrix.
+func Identity(n int) Matrix {
+	out := EmptyMatrix(n, n)
+	for i := range out {
+		out[i][i] = 1
+	}
+
+	return out
+}
+
+// Mul returns the matrix product m · n.
+// Panics unless m has as many columns as n has rows.
+func (m Matrix) Mul(n Matrix) Matrix {
+	if len(m) == 0 || len(n) == 0 {
+		panic("Mul: cannot multiply empty matrices")
+	}
+
+	if len(m[0]) != len(n) {
+		panic(fmt.Sprintf("Mul: cannot multiply a matrix with %d columns by a matrix with %d rows", len(m[0]), len(n)))
+	}
+
+	out := EmptyMatrix(len(n[0]), len(m))
+	for i := range m {
+		for j := range n[0] {
+			var sum byte
+			for k := range n {
+				sum ^= mul(m[i][k], n[k][j])
+			}
+			out[i][j] = sum
+		}
+	}
+
+	return out
+}
+
+// Inverse returns the inverse of a square matrix using Gauss-Jordan elimination,
+// or ErrSingular if there is none. Panics if the matrix is not square.
+func (m Matrix) Inverse() (Matrix, error) {
+	n := m.squareSize("Inverse")
+
+	a := m.clone()
+	inv := Identity(n)
+
+	for col := 0; col < n; col++ {
+		pivot := -1
+		for row := col; row < n; row++ {
+			if a[row][col] != 0 {
+				pivot = row
+				break
+			}
+		}
+		if pivot < 0 {
+			return nil, ErrSingular
+		}
+
+		a[col], a[pivot] = a[pivot], a[col]
+		inv[col], inv[pivot] = inv[pivot], inv[col]
+
+		// Scale the pivot row so that the pivot becomes 1.
+		scale := byte(field.Inv(gf256.Element(a[col][col])))
+		for j := 0; j < n; j++ {
+			a[col][j] = mul(a[col][j], scale)
+			inv[col][j] = mul(inv[col][j], scale)
+		}
+
+		// Eliminate the column from every other row.
+		for row := 0; row < n; row++ {
+			if row == col || a[row][col] == 0 {
+				continue
+			}
+
+			factor := a[row][col]
+			for j := 0; j < n; j++ {
+				a[row][j] ^= mul(factor, a[col][j])
+				inv[row][j] ^= mul(factor, inv[col][j])
+			}
+		}
+	}
+
+	return inv, nil
+}
+
+// Determinant returns the determinant of a square matrix, computed by
+// reducing it to upper triangular form. Swapping rows would flip the sign,
+// which does not matter in characteristic 2.
+// Panics if the matrix is not square.
+func (m Matrix) Determinant() byte {
+	n := m.squareSize("Determinant")
+	a := m.clone()
+
+	det := byte(1)
+	for col := 0; col < n; col++ {
+		pivot := -1
+		for row := col; row < n; row++ {
+			if a[row][col] != 0 {
+				pivot = row
+				break
+			}
+		}
+		if pivot < 0 {
+			return 0
+		}
+
+		a[col], a[pivot] = a[pivot], a[col]
+		det = mul(det, a[col][col])
+
+		inv := byte(field.Inv(gf256.Element(a[col][col])))
+		for row := col + 1; row < n; row++ {
+			factor := mul(a[row][col], inv)
+			for j := col; j < n; j++ {
+				a[row][j] ^= mul(factor, a[col][j])
+			}
+		}
+	}
+
+	return det
+}
+
+// IsMDS reports whether a square matrix is maximum distance separable,
+// i.e. whether every square submatrix is non-singular. As a diffusion layer,
+// such a matrix has the highest possible branch number, n + 1.
+func (m Matrix) IsMDS() bool {
+	n := m.squareSize("IsMDS")
+
+	for size := 1; size <= n; size++ {
+		for _, rows := range combinations(n, size) {
+			for _, columns := range combinations(n, size) {
+				sub := EmptyMatrix(size, size)
+				for i, r := range rows {
+					for j, c := range columns {
+						sub[i][j] = m[r][c]
+					}
+				}
+
+				if sub.Determinant() == 0 {
+					return false
+				}
+			}
+		}
+	}
+
+	return true
+}
+
+// combinations returns every subset of size k of {0, ..., n-1}, in increasing order.
+func combinations(n, k int) [][]int {
+	if k == 0 {
+		return [][]int{{}}
+	}
+
+	var out [][]int
+	for last := k - 1; last < n; last++ {
+		for _, c := range combinations(last, k-1) {
+			out = append(out, append(append([]int{}, c...), last))
+		}
+	}
+
+	return out
+}
+
+func (m Matrix) squareSize(method string) int {
+	n := len(m)
+	for _, row := range m {
+		if len(row) != n {
+			panic(fmt.Sprintf("%s: matrix is not square", method))
+		}
+	}
+
+	return n
+}
+
+func (m Matrix) clone() Matrix {
+	out := make(Matrix, len(m))
+	for i, row := range m {
+		out[i] = append(Vector{}, row...)
+	}
+
+	return out
+}
@@ -0,0 +1,78 @@
+package matrix
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// The MixColumns matrix and its inverse, from FIPS-197 Sections 5.1.3 and 5.3.3.
+var (
+	mixColumns = NewMatrix([]byte{
+		0x02, 0x03, 0x01, 0x01,
+		0x01, 0x02, 0x03, 0x01,
+		0x01, 0x01, 0x02, 0x03,
+		0x03, 0x01, 0x01, 0x02,
+	}, 4)
+	mixColumnsInverse = NewMatrix([]byte{
+		0x0e, 0x0b, 0x0d, 0x09,
+		0x09, 0x0e, 0x0b, 0x0d,
+		0x0d, 0x09, 0x0e, 0x0b,
+		0x0b, 0x0d, 0x09, 0x0e,
+	}, 4)
+)
+
+func TestInverse(t *testing.T) {
+	inv, err := mixColumns.Inverse()
+	require.NoError(t, err)
+	assert.Equal(t, mixColumnsInverse, inv)
+	assert.Equal(t, Identity(4), mixColumns.Mul(inv))
+	assert.Equal(t, Identity(4), inv.Mul(mixColumns))
+
+	// The inverse must not modify the original.
+	assert.Equal(t, byte(0x02), mixColumns[0][0])
+
+	_, err = NewMatrix([]byte{1, 2, 2, 4}, 2).Inverse()
+	assert.ErrorIs(t, err, ErrSingular)
+
+	assert.Panics(t, func() { NewMatrix([]byte{1, 2, 3, 4, 5, 6}, 3).Inverse() })
+}
+
+func TestMul(t *testing.T) {
+	// A 2×3 matrix times a 3×1 column.
+	m := NewMatrix([]byte{1, 2, 3, 4, 5, 6}, 3)
+	v := NewMatrix([]byte{1, 1, 1}, 1)
+	assert.Equal(t, NewMatrix([]byte{1 ^ 2 ^ 3, 4 ^ 5 ^ 6}, 1), m.Mul(v))
+
+	// {57} · {83} = {c1}, see FIPS-197 Section 4.2.
+	assert.Equal(t, NewMatrix([]byte{0xc1}, 1), NewMatrix([]byte{0x57}, 1).Mul(NewMatrix([]byte{0x83}, 1)))
+
+	assert.Panics(t, func() { m.Mul(m) })
+}
+
+func TestDeterminant(t *testing.T) {
+	assert.Equal(t, byte(1), Identity(5).Determinant())
+	assert.Equal(t, byte(0), NewMatrix([]byte{1, 2, 2, 4}, 2).Determinant())
+
+	// ad - bc for a 2×2 matrix, where subtraction is XOR.
+	assert.Equal(t, byte(0x02^0x03), NewMatrix([]byte{0x01, 0x01, 0x03, 0x02}, 2).Determinant())
+
+	// The determinant of a product is the product of the determinants.
+	assert.Equal(t, mul(mixColumns.Determinant(), mixColumnsInverse.Determinant()), byte(1))
+}
+
+func TestIsMDS(t *testing.T) {
+	assert.True(t, mixColumns.IsMDS())
+	assert.True(t, mixColumnsInverse.IsMDS())
+
+	// The identity has zero entries, which are singular 1×1 submatrices.
+	assert.False(t, Identity(4).IsMDS())
+
+	// All entries are non-zero, but the top left 2×2 submatrix is singular.
+	assert.False(t, NewMatrix([]byte{
+		0x01, 0x01, 0x02,
+		0x01, 0x01, 0x03,
+		0x02, 0x03, 0x01,
+	}, 3).IsMDS())
+}
@@ -3,18 +3,35 @@
 package matrix
 
 import (
+	"bytes"
+	"encoding/csv"
+	"errors"
 	"fmt"
+	"strings"
 )
 
+// ErrOutOfRange is returned by the bounds-checked accessors when an index
+// lies outside of a Vector or Matrix.
+var ErrOutOfRange = errors.New("matrix: index out of range")
+
 // NewVector splits an unsigned 32-bit integer into a 4-byte Vector.
 func NewVector(n uint32) Vector {
-	mask := byte(0xff)
-	return Vector{
-		byte(n >> 24),
-		byte(n>>16) & mask,
-		byte(n>>8) & mask,
-		byte(n) & mask,
+	return NewVectorN(uint64(n), 4)
+}
+
+// NewVectorN splits an unsigned integer into a Vector of the given size,
+// most significant byte first. Panics unless size is between 1 and 8.
+func NewVectorN(n uint64, size int) Vector {
+	if size < 1 || size > 8 {
+		panic(fmt.Sprintf("NewVectorN: size must be between 1 and 8 bytes; received %d", size))
+	}
+
+	out := make(Vector, size)
+	for i := range out {
+		out[i] = byte(n >> (8 * (size - 1 - i)))
 	}
+
+	return out
 }
 
 // Vector is a row or column in a Matrix.
@@ -22,7 +39,22 @@ type Vector []byte
 
 // String returns a padded hexadecimal representation of a Vector.
 func (v Vector) String() string {
-	return fmt.Sprintf("| %02x | %02x | %02x | %02x |", v[0], v[1], v[2], v[3])
+	var b strings.Builder
+	for _, x := range v {
+		fmt.Fprintf(&b, "| %02x ", x)
+	}
+	b.WriteString("|")
+
+	return b.String()
+}
+
+// At returns the element at the given index.
+func (v Vector) At(index int) (byte, error) {
+	if index < 0 || index >= len(v) {
+		return 0, fmt.Errorf("%w: element %d of a vector of length %d", ErrOutOfRange, index, len(v))
+	}
+
+	return v[index], nil
 }
 
 // Matrix is a nice way to represent the state and other table-like data
@@ -50,24 +82,129 @@ func EmptyMatrix(columns, rows int) Matrix {
 	return out
 }
 
+// Size returns the number of rows and columns of a Matrix.
+// The number of columns is taken from the first row.
+func (m Matrix) Size() (rows, columns int) {
+	if len(m) == 0 {
+		return 0, 0
+	}
+
+	return len(m), len(m[0])
+}
+
+// At returns the element in the given row and column.
+func (m Matrix) At(row, column int) (byte, error) {
+	if err := m.checkBounds(row, column); err != nil {
+		return 0, err
+	}
+
+	return m[row][column], nil
+}
+
+// Set replaces the element in the given row and column, in place.
+func (m Matrix) Set(row, column int, value byte) error {
+	if err := m.checkBounds(row, column); err != nil {
+		return err
+	}
+
+	m[row][column] = value
+	return nil
+}
+
+// Row returns a copy of the row at the given index.
+func (m Matrix) Row(index int) (Vector, error) {
+	if err := m.checkBounds(index, 0); err != nil {
+		return nil, err
+	}
+
+	return append(Vector{}, m[index]...), nil
+}
+
+// Column returns a copy of the column at the given index.
+func (m Matrix) Column(index int) (Vector, error) {
+	if err := m.checkBounds(0, index); err != nil {
+		return nil, err
+	}
+
+	return ColumnVector(m, index), nil
+}
+
+func (m Matrix) checkBounds(row, column int) error {
+	rows, columns := m.Size()
+	if row < 0 || row >= rows {
+		return fmt.Errorf("%w: row %d of a matrix with %d rows", ErrOutOfRange, row, rows)
+	}
+
+	if column < 0 || column >= columns {
+		return fmt.Errorf("%w: column %d of a matrix with %d columns", ErrOutOfRange, column, columns)
+	}
+
+	return nil
+}
+
 // String returns a padded hexadecimal representation of a Matrix.
 func (m Matrix) String() string {
-	hr := "-------------------------\n"
+	_, columns := m.Size()
+	hr := strings.Repeat("-", 5*columns+1) + "\n"
+
 	out := "\n" + hr
 	for _, row := range m {
-		out += fmt.Sprintf("| %02x | %02x | %02x | %02x |\n", row[0], row[1], row[2], row[3])
+		out += row.String() + "\n"
 	}
 	out += hr
 
 	return out
 }
 
+// CSV returns the Matrix as comma-separated hexadecimal values, one row per line.
+func (m Matrix) CSV() string {
+	var buf bytes.Buffer
+	w := csv.NewWriter(&buf)
+	for _, row := range m {
+		// Writing to a bytes.Buffer cannot fail.
+		_ = w.Write(hexCells(row))
+	}
+	w.Flush()
+
+	return buf.String()
+}
+
+// Markdown returns the Matrix as a Markdown table, with the column indices as header.
+func (m Matrix) Markdown() string {
+	_, columns := m.Size()
+
+	var b strings.Builder
+	header := make([]string, columns)
+	separator := make([]string, columns)
+	for i := range header {
+		header[i] = fmt.Sprint(i)
+		separator[i] = "---"
+	}
+	fmt.Fprintf(&b, "| %s |\n", strings.Join(header, " | "))
+	fmt.Fprintf(&b, "| %s |\n", strings.Join(separator, " | "))
+
+	for _, row := range m {
+		fmt.Fprintf(&b, "| %s |\n", strings.Join(hexCells(row), " | "))
+	}
+
+	return b.String()
+}
+
+func hexCells(v Vector) []string {
+	out := make([]string, len(v))
+	for i, x := range v {
+		out[i] = fmt.Sprintf("%02x", x)
+	}
+
+	return out
+}
+
 // Transpose returns a transposed copy of a Matrix.
 func (m Matrix) Transpose() Matrix {
 	out := make(Matrix, 0, len(m[0]))
-	for i := 0; i < len(m); i++ {
+	for i := 0; i < len(m[0]); i++ {
 		out = append(out, make(Vector, len(m)))
-		for j := 0; j < len(m[0]); j++ {
+		for j := 0; j < len(m); j++ {
 			out[i][j] = m[j][i]
 		}
 	}
@@ -78,7 +215,7 @@ func (m Matrix) Transpose() Matrix {
 // SetColumn replaces the column at the given index with the given Vector, in place.
 func (m Matrix) SetColumn(column Vector, index int) {
 	size := len(m)
-	if index > len(m[0]) {
+	if index < 0 || index >= len(m[0]) {
 		panic(fmt.Sprintf("column is %d, but matrix only has %d columns", index, len(m[0])))
 	}
 
@@ -94,8 +231,8 @@ func (m Matrix) SetColumn(column Vector, index int) {
 // SetRow replaces the row at the given index with the given Vector, in place.
 func (m Matrix) SetRow(row Vector, index int) {
 	size := len(m)
-	if index > size {
-		panic(fmt.Sprintf("row is %d, but matrix only has %d rows", row, size))
+	if index < 0 || index >= size {
+		panic(fmt.Sprintf("row is %d, but matrix only has %d rows", index, size))
 	}
 
 	if len(row) != len(m[0]) {
@@ -0,0 +1,96 @@
+package matrix
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewVectorN(t *testing.T) {
+	assert.Equal(t, Vector{0x01, 0x02, 0x03, 0x04}, NewVector(0x01020304))
+	assert.Equal(t, Vector{0x01, 0x02, 0x03, 0x04, 0x05, 0x06}, NewVectorN(0x010203040506, 6))
+	assert.Equal(t, Vector{0x06}, NewVectorN(0x0506, 1))
+
+	assert.Panics(t, func() { NewVectorN(0, 0) })
+	assert.Panics(t, func() { NewVectorN(0, 9) })
+}
+
+func TestString(t *testing.T) {
+	assert.Equal(t, "| 01 | ff |", Vector{0x01, 0xff}.String())
+	assert.Equal(t, "| 01 | 02 | 03 | 04 | 05 | 06 |", NewVectorN(0x010203040506, 6).String())
+
+	m := NewMatrix([]byte{0x00, 0x01, 0x02, 0x03, 0x04, 0x05}, 3)
+	assert.Equal(t, "\n"+
+		"----------------\n"+
+		"| 00 | 01 | 02 |\n"+
+		"| 03 | 04 | 05 |\n"+
+		"----------------\n", m.String())
+}
+
+func TestCSV(t *testing.T) {
+	m := NewMatrix([]byte{0x00, 0x01, 0x02, 0x03, 0x04, 0x05}, 2)
+	assert.Equal(t, "00,01\n02,03\n04,05\n", m.CSV())
+}
+
+func TestMarkdown(t *testing.T) {
+	m := NewMatrix([]byte{0x00, 0x01, 0x02, 0x03, 0x04, 0x05}, 3)
+	assert.Equal(t, ""+
+		"| 0 | 1 | 2 |\n"+
+		"| --- | --- | --- |\n"+
+		"| 00 | 01 | 02 |\n"+
+		"| 03 | 04 | 05 |\n", m.Markdown())
+}
+
+func TestAccessors(t *testing.T) {
+	m := EmptyMatrix(5, 3)
+	rows, columns := m.Size()
+	assert.Equal(t, 3, rows)
+	assert.Equal(t, 5, columns)
+
+	require.NoError(t, m.Set(2, 4, 0xab))
+	v, err := m.At(2, 4)
+	require.NoError(t, err)
+	assert.Equal(t, byte(0xab), v)
+
+	row, err := m.Row(2)
+	require.NoError(t, err)
+	assert.Equal(t, Vector{0, 0, 0, 0, 0xab}, row)
+
+	// Row returns a copy.
+	row[0] = 1
+	assert.Equal(t, byte(0), m[2][0])
+
+	column, err := m.Column(4)
+	require.NoError(t, err)
+	assert.Equal(t, Vector{0, 0, 0xab}, column)
+
+	b, err := row.At(4)
+	require.NoError(t, err)
+	assert.Equal(t, byte(0xab), b)
+
+	for _, err := range []error{
+		m.Set(3, 0, 1),
+		m.Set(0, 5, 1),
+		m.Set(-1, 0, 1),
+		func() error { _, err := m.At(0, -1); return err }(),
+		func() error { _, err := m.Row(3); return err }(),
+		func() error { _, err := m.Column(5); return err }(),
+		func() error { _, err := row.At(5); return err }(),
+	} {
+		assert.True(t, errors.Is(err, ErrOutOfRange), err)
+	}
+}
+
+func TestSetRowColumn(t *testing.T) {
+	m := EmptyMatrix(3, 2)
+	m.SetRow(Vector{1, 2, 3}, 1)
+	m.SetColumn(Vector{4, 5}, 2)
+	assert.Equal(t, Matrix{{0, 0, 4}, {1, 2, 5}}, m)
+
+	// The last valid index is len-1.
+	assert.Panics(t, func() { m.SetRow(Vector{1, 2, 3}, 2) })
+	assert.Panics(t, func() { m.SetColumn(Vector{1, 2}, 3) })
+	assert.Panics(t, func() { m.SetColumn(Vector{1, 2}, -1) })
+}
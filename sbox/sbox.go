@@ -0,0 +1,271 @@
+// Package sbox measures the cryptographic properties of 8-bit S-boxes,
+// such as how well they resist differential and linear cryptanalysis.
+// See 'The Design of Rijndael' Chapter 3 for why AES chose its S-box.
+package sbox
+
+import (
+	"bufio"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"math/bits"
+	"strconv"
+	"strings"
+
+	"github.com/intersesh/crypto/aes"
+)
+
+// SBox is a substitution table on bytes.
+type SBox [256]byte
+
+// AES is the S-box from FIPS-197 Section 5.1.1.
+var AES = func() (out SBox) {
+	for i := range out {
+		out[i] = aes.SBox(byte(i))
+	}
+
+	return out
+}()
+
+// ErrInvalidSBox is returned by Parse when the input is not made of 256 bytes.
+var ErrInvalidSBox = errors.New("sbox: expected 256 hexadecimal bytes")
+
+// Parse reads an S-box as 256 hexadecimal bytes, separated by whitespace or
+// commas, with or without a 0x prefix. This accepts the layout of FIPS-197
+// Figure 7 without the row and column headers, as well as C array literals.
+func Parse(r io.Reader) (SBox, error) {
+	var out SBox
+
+	scanner := bufio.NewScanner(r)
+	scanner.Split(bufio.ScanWords)
+
+	n := 0
+	for scanner.Scan() {
+		for _, field := range strings.Split(scanner.Text(), ",") {
+			if field == "" {
+				continue
+			}
+			if n == len(out) {
+				return SBox{}, fmt.Errorf("%w: found more than %d", ErrInvalidSBox, len(out))
+			}
+
+			b, err := strconv.ParseUint(strings.TrimPrefix(strings.ToLower(field), "0x"), 16, 8)
+			if err != nil {
+				return SBox{}, fmt.Errorf("%w: %q", ErrInvalidSBox, field)
+			}
+
+			out[n] = byte(b)
+			n++
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return SBox{}, err
+	}
+
+	if n != len(out) {
+		return SBox{}, fmt.Errorf("%w: found %d", ErrInvalidSBox, n)
+	}
+
+	return out, nil
+}
+
+// Table holds one count for every pair of input and output mask or difference,
+// indexed as [input][output].
+type Table [256][256]int
+
+// WriteCSV writes the table as 256 rows of 256 comma-separated values,
+// preceded by a header row with the output indices.
+func (t *Table) WriteCSV(w io.Writer) error {
+	out := csv.NewWriter(w)
+
+	record := make([]string, len(t)+1)
+	record[0] = "in\\out"
+	for i := range t {
+		record[i+1] = fmt.Sprintf("%02x", i)
+	}
+	if err := out.Write(record); err != nil {
+		return err
+	}
+
+	for a, row := range t {
+		record[0] = fmt.Sprintf("%02x", a)
+		for b, v := range row {
+			record[b+1] = strconv.Itoa(v)
+		}
+
+		if err := out.Write(record); err != nil {
+			return err
+		}
+	}
+
+	out.Flush()
+	return out.Error()
+}
+
+// IsBijective reports whether every output occurs exactly once.
+func (s *SBox) IsBijective() bool {
+	var seen [256]bool
+	for _, y := range s {
+		if seen[y] {
+			return false
+		}
+		seen[y] = true
+	}
+
+	return true
+}
+
+// DDT returns the difference distribution table, where entry [a][b] counts
+// the inputs x for which S(x) ⊕ S(x ⊕ a) = b.
+func (s *SBox) DDT() *Table {
+	t := &Table{}
+	for a := 0; a < 256; a++ {
+		for x := 0; x < 256; x++ {
+			t[a][s[x]^s[x^a]]++
+		}
+	}
+
+	return t
+}
+
+// LAT returns the linear approximation table, where entry [a][b] is the number
+// of inputs x for which a·x = b·S(x), minus 128. The dot product is the parity
+// of the bits that are set in both operands.
+func (s *SBox) LAT() *Table {
+	t := &Table{}
+	for a := 0; a < 256; a++ {
+		for b := 0; b < 256; b++ {
+			count := 0
+			for x := 0; x < 256; x++ {
+				if bits.OnesCount8(byte(a)&byte(x))&1 == bits.OnesCount8(byte(b)&s[x])&1 {
+					count++
+				}
+			}
+
+			t[a][b] = count - 128
+		}
+	}
+
+	return t
+}
+
+// DifferentialUniformity returns the largest entry of the DDT for a nonzero
+// input difference. The lowest possible value for a bijection is 2, and no 8-bit
+// bijection with a value of 2 is known; AES achieves 4.
+func (s *SBox) DifferentialUniformity() int {
+	ddt := s.DDT()
+
+	out := 0
+	for a := 1; a < 256; a++ {
+		for _, v := range ddt[a] {
+			if v > out {
+				out = v
+			}
+		}
+	}
+
+	return out
+}
+
+// Nonlinearity returns the distance between the components of the S-box and
+// the closest affine function, which is 128 minus the largest absolute LAT
+// entry for a nonzero output mask. AES achieves 112.
+func (s *SBox) Nonlinearity() int {
+	lat := s.LAT()
+
+	bias := 0
+	for a := range lat {
+		for b := 1; b < 256; b++ {
+			v := lat[a][b]
+			if v < 0 {
+				v = -v
+			}
+			if v > bias {
+				bias = v
+			}
+		}
+	}
+
+	return 128 - bias
+}
+
+// AlgebraicDegree returns the highest degree of the algebraic normal form of
+// any output bit, as a polynomial in the input bits. Affine S-boxes have degree 1
+// at most, and bijections at most 7.
+func (s *SBox) AlgebraicDegree() int {
+	degree := 0
+	for bit := 0; bit < 8; bit++ {
+		var anf [256]byte
+		for x, y := range s {
+			anf[x] = y >> bit & 1
+		}
+
+		// The Möbius transform turns a truth table into the coefficients of
+		// the algebraic normal form, in place.
+		for step := 1; step < 256; step <<= 1 {
+			for x := 0; x < 256; x++ {
+				if x&step != 0 {
+					anf[x] ^= anf[x^step]
+				}
+			}
+		}
+
+		for monomial, coefficient := range anf {
+			if d := bits.OnesCount8(byte(monomial)); coefficient == 1 && d > degree {
+				degree = d
+			}
+		}
+	}
+
+	return degree
+}
+
+// FixedPoints returns the inputs x for which S(x) = x, in increasing order.
+// The constant in the affine transformation of AES was chosen so that there are none.
+func (s *SBox) FixedPoints() []byte {
+	var out []byte
+	for x, y := range s {
+		if byte(x) == y {
+			out = append(out, y)
+		}
+	}
+
+	return out
+}
+
+// Report summarises the properties of an S-box.
+type Report struct {
+	Bijective              bool
+	DifferentialUniformity int
+	Nonlinearity           int
+	AlgebraicDegree        int
+	FixedPoints            []byte
+}
+
+// Analyze computes every property in a Report.
+func (s *SBox) Analyze() Report {
+	return Report{
+		Bijective:              s.IsBijective(),
+		DifferentialUniformity: s.DifferentialUniformity(),
+		Nonlinearity:           s.Nonlinearity(),
+		AlgebraicDegree:        s.AlgebraicDegree(),
+		FixedPoints:            s.FixedPoints(),
+	}
+}
+
+// String formats the report with one property per line.
+func (r Report) String() string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "bijective:               %t\n", r.Bijective)
+	fmt.Fprintf(&b, "differential uniformity: %d\n", r.DifferentialUniformity)
+	fmt.Fprintf(&b, "nonlinearity:            %d\n", r.Nonlinearity)
+	fmt.Fprintf(&b, "algebraic degree:        %d\n", r.AlgebraicDegree)
+	fmt.Fprintf(&b, "fixed points:            %d", len(r.FixedPoints))
+	for _, x := range r.FixedPoints {
+		fmt.Fprintf(&b, " %02x", x)
+	}
+	b.WriteString("\n")
+
+	return b.String()
+}
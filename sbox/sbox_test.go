@@ -0,0 +1,127 @@
+package sbox
+
+import (
+	"bytes"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/intersesh/crypto/aes"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var identity = func() (out SBox) {
+	for i := range out {
+		out[i] = byte(i)
+	}
+
+	return out
+}()
+
+// The properties of the AES S-box are listed in 'The Design of Rijndael' Section 3.6.1.
+func TestAES(t *testing.T) {
+	assert.Equal(t, Report{
+		Bijective:              true,
+		DifferentialUniformity: 4,
+		Nonlinearity:           112,
+		AlgebraicDegree:        7,
+	}, AES.Analyze())
+
+	var inverse SBox
+	for i := range inverse {
+		inverse[i] = aes.InvSBox(byte(i))
+	}
+	r := inverse.Analyze()
+	assert.Equal(t, 4, r.DifferentialUniformity)
+	assert.Equal(t, 112, r.Nonlinearity)
+	assert.Equal(t, 7, r.AlgebraicDegree)
+}
+
+// TestDDT checks that every row with a nonzero input difference has a single
+// entry of 4, and 126 entries of 2.
+func TestDDT(t *testing.T) {
+	ddt := AES.DDT()
+	assert.Equal(t, 256, ddt[0][0])
+
+	for a := 1; a < 256; a++ {
+		counts := map[int]int{}
+		for _, v := range ddt[a] {
+			counts[v]++
+		}
+
+		assert.Equal(t, map[int]int{0: 129, 2: 126, 4: 1}, counts, "row %02x", a)
+	}
+}
+
+func TestLAT(t *testing.T) {
+	lat := AES.LAT()
+	assert.Equal(t, 128, lat[0][0])
+
+	for a := 0; a < 256; a++ {
+		for b := 0; b < 256; b++ {
+			// Every entry of a bijection is even, and only the trivial
+			// approximation holds for every input.
+			assert.Zero(t, lat[a][b]%2)
+			if a != 0 || b != 0 {
+				assert.LessOrEqual(t, lat[a][b], 16)
+				assert.GreaterOrEqual(t, lat[a][b], -16)
+			}
+		}
+	}
+}
+
+func TestIdentity(t *testing.T) {
+	r := identity.Analyze()
+	assert.True(t, r.Bijective)
+	assert.Equal(t, 256, r.DifferentialUniformity)
+	assert.Equal(t, 0, r.Nonlinearity)
+	assert.Equal(t, 1, r.AlgebraicDegree)
+	assert.Len(t, r.FixedPoints, 256)
+}
+
+func TestIsBijective(t *testing.T) {
+	s := AES
+	s[0] = s[1]
+	assert.False(t, s.IsBijective())
+	assert.True(t, AES.IsBijective())
+	assert.Empty(t, AES.FixedPoints())
+}
+
+func TestParse(t *testing.T) {
+	var fips, c strings.Builder
+	for i, y := range AES {
+		fmt.Fprintf(&fips, "%02x", y)
+		fmt.Fprintf(&c, "0x%02X,", y)
+		if i%16 == 15 {
+			fips.WriteString("\n")
+		} else {
+			fips.WriteString(" ")
+		}
+	}
+
+	for _, in := range []string{fips.String(), c.String()} {
+		s, err := Parse(strings.NewReader(in))
+		require.NoError(t, err)
+		assert.Equal(t, AES, s)
+	}
+
+	for _, in := range []string{"", "00 01", "zz " + fips.String(), fips.String() + " 00", "100"} {
+		_, err := Parse(strings.NewReader(in))
+		assert.True(t, errors.Is(err, ErrInvalidSBox), "%q: %v", in, err)
+	}
+}
+
+func TestWriteCSV(t *testing.T) {
+	var buf bytes.Buffer
+	require.NoError(t, AES.DDT().WriteCSV(&buf))
+
+	records, err := csv.NewReader(&buf).ReadAll()
+	require.NoError(t, err)
+	require.Len(t, records, 257)
+	assert.Len(t, records[0], 257)
+	assert.Equal(t, "ff", records[0][256])
+	assert.Equal(t, []string{"00", "256"}, records[1][:2])
+}
@@ -0,0 +1,106 @@
+package walkthrough
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/intersesh/crypto/matrix"
+)
+
+// ANSI escape codes for highlighting changed bytes.
+const (
+	reverse = "\x1b[7m"
+	reset   = "\x1b[0m"
+)
+
+// Render returns the state before and after the operation next to each other,
+// followed by the round key for AddRoundKey. Bytes that changed are marked
+// with a '*' or, if color is set, shown in reverse video:
+//
+//	round 1: SubBytes (FIPS-197 Section 5.1.1), 16 of 16 bytes changed
+//
+//	before                   after
+//	---------------------    ---------------------
+//	| 19 | a0 | 9a | e9 |    |*d4 |*e0 |*b8 |*1e |
+func (o Operation) Render(color bool) string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "round %d: %s", o.Round, o.Name)
+	if section := o.Section(); section != "" {
+		fmt.Fprintf(&b, " (FIPS-197 Section %s)", section)
+	}
+	fmt.Fprintf(&b, ", %d of 16 bytes changed\n\n", o.CountChanged())
+
+	titles := []string{"before", "after"}
+	columns := [][]string{lines(o.Before.String()), o.renderAfter(color)}
+	if o.RoundKey != nil {
+		titles = append(titles, "round key")
+		columns = append(columns, lines(o.RoundKey.String()))
+	}
+
+	// Every line of a 4×4 matrix is 21 characters wide, not counting escape codes.
+	const width, gap = 21, "    "
+	var header strings.Builder
+	for i, title := range titles {
+		if i > 0 {
+			header.WriteString(gap)
+		}
+		fmt.Fprintf(&header, "%-*s", width, title)
+	}
+	b.WriteString(strings.TrimRight(header.String(), " ") + "\n")
+
+	for i := range columns[0] {
+		for j, column := range columns {
+			if j > 0 {
+				b.WriteString(gap)
+			}
+			b.WriteString(column[i])
+		}
+		b.WriteString("\n")
+	}
+
+	return b.String()
+}
+
+// renderAfter renders the state after the operation like matrix.Matrix.String,
+// but marks the bytes that changed.
+func (o Operation) renderAfter(color bool) []string {
+	out := lines(o.After.String())
+
+	for row, v := range o.After {
+		var line strings.Builder
+		for column, x := range v {
+			switch {
+			case !o.Changed(row, column):
+				fmt.Fprintf(&line, "| %02x ", x)
+			case color:
+				fmt.Fprintf(&line, "| %s%02x%s ", reverse, x, reset)
+			default:
+				fmt.Fprintf(&line, "|*%02x ", x)
+			}
+		}
+		line.WriteString("|")
+
+		// The first line is the horizontal rule above the rows.
+		out[row+1] = line.String()
+	}
+
+	return out
+}
+
+// lines splits the output of matrix.Matrix.String, which starts with an empty line.
+func lines(s string) []string {
+	return strings.Split(strings.Trim(s, "\n"), "\n")
+}
+
+// State returns a matrix as 16 hexadecimal bytes in the column-major order
+// used by FIPS-197, e.g. to show the input and output of the cipher.
+func State(m matrix.Matrix) string {
+	var b strings.Builder
+	for column := range m[0] {
+		for row := range m {
+			fmt.Fprintf(&b, "%02x", m[row][column])
+		}
+	}
+
+	return b.String()
+}
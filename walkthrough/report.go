@@ -0,0 +1,175 @@
+package walkthrough
+
+import (
+	_ "embed"
+	"fmt"
+	"html/template"
+	"io"
+
+	"github.com/intersesh/crypto/aes"
+	"github.com/intersesh/crypto/blockcipher"
+	"github.com/intersesh/crypto/matrix"
+)
+
+// Report holds everything needed to explain one encryption,
+// collected from an aes.Recorder and the key schedule.
+type Report struct {
+	Key      []byte
+	Input    blockcipher.Block
+	Output   blockcipher.Block
+	Schedule aes.KeySchedule
+	// KeyExpansion holds the intermediate values for every word of the
+	// schedule after the key itself.
+	KeyExpansion []aes.KeyExpansionStep
+	Operations   []Operation
+	// MixColumn spells out the arithmetic of the first MixColumns operation
+	// for the first column of the state.
+	MixColumn ColumnMixing
+}
+
+// ColumnMixing is the multiplication of one column of the state by the
+// MixColumns matrix, with every product in GF(2⁸) written out.
+type ColumnMixing struct {
+	Round  int
+	Column int
+	Input  [4]byte
+	Rows   [4]MixedByte
+}
+
+// MixedByte is one byte of a column after MixColumns: the sum of four products.
+type MixedByte struct {
+	Products [4]Product
+	Result   byte
+}
+
+// Product is the multiplication of a byte of the state by a coefficient of
+// the MixColumns matrix, which is one of {01}, {02} or {03}.
+type Product struct {
+	Coefficient byte
+	Byte        byte
+	Result      byte
+}
+
+// Derivation explains the product in terms of xtime, see FIPS-197 Section 4.2.1,
+// e.g. "{03} • {bf} = {bf} ⊕ xtime({bf}) = {bf} ⊕ {65} = {da}".
+func (p Product) Derivation() string {
+	prefix := fmt.Sprintf("{%02x} • {%02x} = ", p.Coefficient, p.Byte)
+
+	switch p.Coefficient {
+	case 1:
+		return fmt.Sprintf("%s{%02x}", prefix, p.Result)
+	case 2:
+		return fmt.Sprintf("%sxtime({%02x}) = {%02x}", prefix, p.Byte, p.Result)
+	case 3:
+		return fmt.Sprintf("%s{%02x} ⊕ xtime({%02x}) = {%02x} ⊕ {%02x} = {%02x}",
+			prefix, p.Byte, p.Byte, p.Byte, aes.Xtime(p.Byte), p.Result)
+	default:
+		return fmt.Sprintf("%s{%02x}", prefix, p.Result)
+	}
+}
+
+// NewReport encrypts block with key and records every step for the report.
+func NewReport(key aes.Key, block blockcipher.Block, opts ...aes.Option) *Report {
+	var r aes.Recorder
+	c := aes.NewCipher(key, append(opts[:len(opts):len(opts)], aes.WithTracer(&r))...)
+
+	report := &Report{
+		Key:          key.Bytes(),
+		Input:        block,
+		Output:       c.Encrypt(block),
+		Schedule:     c.KeySchedule(),
+		KeyExpansion: aes.KeyExpansion(key),
+		Operations:   Operations(r.Events()),
+	}
+
+	for _, op := range report.Operations {
+		if op.Name == MixColumns {
+			report.MixColumn = mixColumn(op, 0)
+			break
+		}
+	}
+
+	return report
+}
+
+// mixColumn takes the column of the state before op and redoes the
+// multiplication by the MixColumns matrix one product at a time.
+func mixColumn(op Operation, column int) ColumnMixing {
+	out := ColumnMixing{Round: op.Round, Column: column}
+	for row := range out.Input {
+		out.Input[row] = op.Before[row][column]
+	}
+
+	coefficients := aes.MixColumnsMatrix()
+	for row := range out.Rows {
+		for i, b := range out.Input {
+			p := Product{Coefficient: coefficients[row][i], Byte: b, Result: aes.Multiply(coefficients[row][i], b)}
+			out.Rows[row].Products[i] = p
+			out.Rows[row].Result ^= p.Result
+		}
+	}
+
+	return out
+}
+
+// Round is a group of operations with the same round number.
+type Round struct {
+	Number     int
+	Operations []Operation
+}
+
+// Rounds groups the operations by round.
+func (r *Report) Rounds() []Round {
+	var out []Round
+	for _, op := range r.Operations {
+		if len(out) == 0 || out[len(out)-1].Number != op.Round {
+			out = append(out, Round{Number: op.Round})
+		}
+		out[len(out)-1].Operations = append(out[len(out)-1].Operations, op)
+	}
+
+	return out
+}
+
+//go:embed report.html.tmpl
+var reportTemplate string
+
+var reportHTML = template.Must(template.New("report").Funcs(template.FuncMap{
+	"hex":      func(b []byte) string { return fmt.Sprintf("%x", b) },
+	"blockHex": func(b blockcipher.Block) string { return fmt.Sprintf("%x", b[:]) },
+	"word":     func(w aes.Word) string { return fmt.Sprintf("%08x", uint32(w)) },
+	"byte":     func(b byte) string { return fmt.Sprintf("%02x", b) },
+	"cells":    cells,
+	"div":      func(a, b int) int { return a / b },
+	"mod":      func(a, b int) int { return a % b },
+	"words":    func(bytes int) int { return bytes / 4 },
+}).Parse(reportTemplate))
+
+// cell is a byte of a state matrix as shown in the report.
+type cell struct {
+	Value   string
+	Changed bool
+}
+
+// cells lays out a matrix for the template. If before is not nil,
+// the bytes that differ from it are marked as changed.
+func cells(m, before matrix.Matrix) [][]cell {
+	out := make([][]cell, len(m))
+	for row, v := range m {
+		out[row] = make([]cell, len(v))
+		for column, b := range v {
+			out[row][column] = cell{
+				Value:   fmt.Sprintf("%02x", b),
+				Changed: before != nil && before[row][column] != b,
+			}
+		}
+	}
+
+	return out
+}
+
+// WriteHTML writes the report as a single HTML page without any external
+// resources, so that it can be opened offline.
+func (r *Report) WriteHTML(w io.Writer) error {
+	return reportHTML.Execute(w, r)
+}
@@ -0,0 +1,58 @@
+package walkthrough
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestMixColumn uses the first column of the example in FIPS-197 Appendix B,
+// where MixColumns turns d4bf5d30 into 046681e5.
+func TestMixColumn(t *testing.T) {
+	r := NewReport(key, input)
+	m := r.MixColumn
+
+	assert.Equal(t, 1, m.Round)
+	assert.Equal(t, [4]byte{0xd4, 0xbf, 0x5d, 0x30}, m.Input)
+	for i, want := range []byte{0x04, 0x66, 0x81, 0xe5} {
+		assert.Equal(t, want, m.Rows[i].Result)
+	}
+
+	assert.Equal(t, "{02} • {d4} = xtime({d4}) = {b3}", m.Rows[0].Products[0].Derivation())
+	assert.Equal(t, "{03} • {bf} = {bf} ⊕ xtime({bf}) = {bf} ⊕ {65} = {da}", m.Rows[0].Products[1].Derivation())
+	assert.Equal(t, "{01} • {5d} = {5d}", m.Rows[0].Products[2].Derivation())
+}
+
+func TestReport(t *testing.T) {
+	r := NewReport(key, input)
+	assert.Equal(t, ciphertext, r.Output)
+	assert.Len(t, r.Schedule, 44)
+
+	rounds := r.Rounds()
+	require.Len(t, rounds, 11)
+	assert.Len(t, rounds[0].Operations, 1)
+	assert.Len(t, rounds[1].Operations, 4)
+	assert.Len(t, rounds[10].Operations, 3)
+
+	var buf bytes.Buffer
+	require.NoError(t, r.WriteHTML(&buf))
+	page := buf.String()
+
+	assert.True(t, strings.HasPrefix(page, "<!DOCTYPE html>"))
+	assert.Contains(t, page, "3925841d02dc09fbdc118597196a0b32")
+	assert.Len(t, r.KeyExpansion, 40)
+	// The first and last words of the key schedule in FIPS-197 Appendix A.1.
+	assert.Contains(t, page, "<td>0</td><td></td><td></td><td></td><td></td><td></td><td></td><td class=\"mono\">2b7e1516</td>")
+	assert.Contains(t, page, "<td class=\"mono\">575c006e</td><td class=\"mono\">b6630ca6</td>")
+	// RotWord and SubWord of w[3] for w[4].
+	assert.Contains(t, page, "<td class=\"mono\">cf4f3c09</td>\n<td class=\"mono\">8a84eb01</td>")
+	assert.Contains(t, page, "s'[0] = {b3} ⊕ {da} ⊕ {5d} ⊕ {30} = {04}")
+	assert.Contains(t, page, `<td class="byte changed">d4</td>`)
+
+	// The page has to work offline.
+	assert.NotContains(t, page, "http")
+	assert.NotContains(t, page, "src=")
+}
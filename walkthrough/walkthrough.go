@@ -0,0 +1,146 @@
+// Package walkthrough turns the steps recorded by an aes.Recorder into the
+// individual operations of the cipher, each with the state before and after
+// it, so that an encryption or decryption can be explained one operation at a time.
+package walkthrough
+
+import (
+	"github.com/intersesh/crypto/aes"
+	"github.com/intersesh/crypto/blockcipher"
+	"github.com/intersesh/crypto/matrix"
+)
+
+// Names of the operations, as used by FIPS-197.
+const (
+	SubBytes         = "SubBytes"
+	ShiftRows        = "ShiftRows"
+	MixColumns       = "MixColumns"
+	AddRoundKey      = "AddRoundKey"
+	InvSubBytes      = "InvSubBytes"
+	InvShiftRows     = "InvShiftRows"
+	InvMixColumns    = "InvMixColumns"
+	unknownOperation = "?"
+)
+
+// sections are the sections of FIPS-197 that describe each operation.
+var sections = map[string]string{
+	SubBytes:      "5.1.1",
+	ShiftRows:     "5.1.2",
+	MixColumns:    "5.1.3",
+	AddRoundKey:   "5.1.4",
+	InvShiftRows:  "5.3.1",
+	InvSubBytes:   "5.3.2",
+	InvMixColumns: "5.3.3",
+}
+
+// Operation is a single transformation of the state.
+type Operation struct {
+	Round  int
+	Name   string
+	Before matrix.Matrix
+	After  matrix.Matrix
+	// RoundKey is the key added by AddRoundKey, with one word per column,
+	// and nil for every other operation.
+	RoundKey matrix.Matrix
+}
+
+// Section returns the section of FIPS-197 that describes the operation.
+func (o Operation) Section() string {
+	return sections[o.Name]
+}
+
+// Changed reports whether the byte in the given row and column of the state
+// has a different value after the operation.
+func (o Operation) Changed(row, column int) bool {
+	return o.Before[row][column] != o.After[row][column]
+}
+
+// CountChanged returns the number of bytes of the state that have a different value after the operation.
+func (o Operation) CountChanged() int {
+	out := 0
+	for row := range o.After {
+		for column := range o.After[row] {
+			if o.Changed(row, column) {
+				out++
+			}
+		}
+	}
+
+	return out
+}
+
+// Operations splits a recording of Encrypt or Decrypt into operations.
+// The operation between two recorded states is AddRoundKey if a round key
+// was traced in between, and is otherwise named after the later step.
+func Operations(events []aes.Event) []Operation {
+	var (
+		out      []Operation
+		previous matrix.Matrix
+		key      *aes.Event
+	)
+
+	for i, e := range events {
+		if e.Step == aes.StepKeySchedule || e.Step == aes.StepInverseKeySchedule {
+			key = &events[i]
+			continue
+		}
+
+		if previous != nil {
+			op := Operation{Round: e.Round, Name: name(e.Step), Before: previous, After: e.State}
+
+			// Encrypt traces the result of AddRoundKey as the start of the
+			// next round, so the round is taken from the key instead.
+			// The same holds for InvMixColumns in Decrypt, but not with
+			// aes.WithEquivalentInverse, which traces StepInverseMixColumns.
+			switch {
+			case key != nil:
+				op.Round, op.Name, op.RoundKey = key.Round, AddRoundKey, key.State
+			case e.Step == aes.StepInverseStart:
+				op.Round--
+			}
+
+			out = append(out, op)
+		}
+
+		previous, key = e.State, nil
+	}
+
+	return out
+}
+
+func name(step aes.Step) string {
+	switch step {
+	case aes.StepSubBytes:
+		return SubBytes
+	case aes.StepShiftRows:
+		return ShiftRows
+	case aes.StepMixColumns:
+		return MixColumns
+	case aes.StepInverseSubBytes:
+		return InvSubBytes
+	case aes.StepInverseShiftRows:
+		return InvShiftRows
+	// Decrypt applies InvMixColumns without tracing it, so its result
+	// shows up as the start of the next round. The Equivalent Inverse
+	// Cipher traces it, and its next round starts after AddRoundKey.
+	case aes.StepInverseMixColumns, aes.StepInverseStart:
+		return InvMixColumns
+	default:
+		return unknownOperation
+	}
+}
+
+// Encryption returns the operations performed to encrypt block.
+func Encryption(key aes.Key, block blockcipher.Block, opts ...aes.Option) []Operation {
+	var r aes.Recorder
+	aes.NewCipher(key, append(opts[:len(opts):len(opts)], aes.WithTracer(&r))...).Encrypt(block)
+
+	return Operations(r.Events())
+}
+
+// Decryption returns the operations performed to decrypt block.
+func Decryption(key aes.Key, block blockcipher.Block, opts ...aes.Option) []Operation {
+	var r aes.Recorder
+	aes.NewCipher(key, append(opts[:len(opts):len(opts)], aes.WithTracer(&r))...).Decrypt(block)
+
+	return Operations(r.Events())
+}
@@ -0,0 +1,161 @@
+package walkthrough
+
+import (
+	"encoding/hex"
+	"strings"
+	"testing"
+
+	"github.com/intersesh/crypto/aes"
+	"github.com/intersesh/crypto/blockcipher"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func mustDecode(s string) []byte {
+	b, err := hex.DecodeString(s)
+	if err != nil {
+		panic(err)
+	}
+
+	return b
+}
+
+// The cipher example from FIPS-197 Appendix B.
+var (
+	key        = aes.NewKey(mustDecode("2b7e151628aed2a6abf7158809cf4f3c"))
+	input      = blockcipher.NewBlock(mustDecode("3243f6a8885a308d313198a2e0370734"))
+	ciphertext = blockcipher.NewBlock(mustDecode("3925841d02dc09fbdc118597196a0b32"))
+)
+
+func TestEncryption(t *testing.T) {
+	ops := Encryption(key, input)
+	require.Len(t, ops, 1+4*9+3)
+
+	for i, want := range []struct {
+		round       int
+		name, after string
+	}{
+		{0, AddRoundKey, "193de3bea0f4e22b9ac68d2ae9f84808"},
+		{1, SubBytes, "d42711aee0bf98f1b8b45de51e415230"},
+		{1, ShiftRows, "d4bf5d30e0b452aeb84111f11e2798e5"},
+		{1, MixColumns, "046681e5e0cb199a48f8d37a2806264c"},
+		{1, AddRoundKey, "a49c7ff2689f352b6b5bea43026a5049"},
+	} {
+		assert.Equal(t, want.round, ops[i].Round, i)
+		assert.Equal(t, want.name, ops[i].Name, i)
+		assert.Equal(t, want.after, State(ops[i].After), i)
+		if i > 0 {
+			assert.Equal(t, ops[i-1].After, ops[i].Before)
+		}
+	}
+
+	assert.Equal(t, "2b7e151628aed2a6abf7158809cf4f3c", State(ops[0].RoundKey))
+	assert.Equal(t, "a0fafe1788542cb123a339392a6c7605", State(ops[4].RoundKey))
+	assert.Nil(t, ops[1].RoundKey)
+
+	last := ops[len(ops)-1]
+	assert.Equal(t, 10, last.Round)
+	assert.Equal(t, AddRoundKey, last.Name)
+	assert.Equal(t, hex.EncodeToString(ciphertext[:]), State(last.After))
+	assert.Equal(t, ShiftRows, ops[len(ops)-2].Name)
+
+	// ShiftRows leaves the first row where it is, and the example happens to
+	// have no other byte that is equal to its neighbour.
+	assert.Equal(t, 12, ops[2].CountChanged())
+	assert.False(t, ops[2].Changed(0, 1))
+	assert.True(t, ops[2].Changed(1, 1))
+}
+
+func TestDecryption(t *testing.T) {
+	for _, opts := range [][]aes.Option{nil, {aes.WithEquivalentInverse()}} {
+		ops := Decryption(key, ciphertext, opts...)
+		require.Len(t, ops, 1+4*9+3)
+
+		assert.Equal(t, 0, ops[0].Round)
+		assert.Equal(t, AddRoundKey, ops[0].Name)
+		assert.Equal(t, "d014f9a8c9ee2589e13f0cc8b6630ca6", State(ops[0].RoundKey))
+
+		for _, op := range ops {
+			assert.NotEqual(t, unknownOperation, op.Name)
+			assert.NotEmpty(t, op.Section())
+		}
+
+		last := ops[len(ops)-1]
+		assert.Equal(t, 10, last.Round)
+		assert.Equal(t, hex.EncodeToString(input[:]), State(last.After))
+	}
+}
+
+// TestDecryptionRounds checks the name and round of every operation for both
+// decryption paths. Decrypt does not trace InvMixColumns, but the Equivalent
+// Inverse Cipher does, and traces the round key after it.
+func TestDecryptionRounds(t *testing.T) {
+	for _, test := range []struct {
+		name  string
+		opts  []aes.Option
+		round []string
+	}{
+		{
+			name:  "InvCipher",
+			round: []string{InvShiftRows, InvSubBytes, AddRoundKey, InvMixColumns},
+		},
+		{
+			name:  "EqInvCipher",
+			opts:  []aes.Option{aes.WithEquivalentInverse()},
+			round: []string{InvSubBytes, InvShiftRows, InvMixColumns, AddRoundKey},
+		},
+	} {
+		t.Run(test.name, func(t *testing.T) {
+			type step struct {
+				Round int
+				Name  string
+			}
+
+			want := []step{{0, AddRoundKey}}
+			for round := 1; round < 10; round++ {
+				for _, name := range test.round {
+					want = append(want, step{round, name})
+				}
+			}
+			for _, name := range test.round {
+				if name != InvMixColumns {
+					want = append(want, step{10, name})
+				}
+			}
+
+			ops := Decryption(key, ciphertext, test.opts...)
+
+			var got []step
+			for _, op := range ops {
+				got = append(got, step{op.Round, op.Name})
+			}
+			assert.Equal(t, want, got)
+			assert.Equal(t, hex.EncodeToString(input[:]), State(ops[len(ops)-1].After))
+		})
+	}
+}
+
+func TestRender(t *testing.T) {
+	ops := Encryption(key, input)
+
+	assert.Equal(t, ""+
+		"round 1: ShiftRows (FIPS-197 Section 5.1.2), 12 of 16 bytes changed\n"+
+		"\n"+
+		"before                   after\n"+
+		"---------------------    ---------------------\n"+
+		"| d4 | e0 | b8 | 1e |    | d4 | e0 | b8 | 1e |\n"+
+		"| 27 | bf | b4 | 41 |    |*bf |*b4 |*41 |*27 |\n"+
+		"| 11 | 98 | 5d | 52 |    |*5d |*52 |*11 |*98 |\n"+
+		"| ae | f1 | e5 | 30 |    |*30 |*ae |*f1 |*e5 |\n"+
+		"---------------------    ---------------------\n", ops[2].Render(false))
+
+	rendered := ops[4].Render(true)
+	assert.Contains(t, rendered, "round key")
+	assert.Contains(t, rendered, "| "+reverse+"a4"+reset+" |")
+
+	for _, line := range strings.Split(ops[4].Render(false), "\n")[3:] {
+		if line != "" {
+			assert.Len(t, line, 3*21+2*4)
+		}
+	}
+}
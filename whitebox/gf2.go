@@ -0,0 +1,137 @@
+package whitebox
+
+import (
+	"math/bits"
+	"math/rand"
+)
+
+// binaryMatrix is a square matrix over GF(2) with up to 32 rows,
+// where bit n-1-j of row i holds the entry in column j.
+// Multiplying by a vector is then a parity check per row.
+type binaryMatrix []uint32
+
+// apply multiplies the matrix by the column vector x.
+func (m binaryMatrix) apply(x uint32) uint32 {
+	var out uint32
+	for _, row := range m {
+		out = out<<1 | uint32(bits.OnesCount32(row&x)&1)
+	}
+
+	return out
+}
+
+// randomBinaryMatrix returns a random invertible n×n matrix over GF(2)
+// together with its inverse. About 29% of random matrices are invertible,
+// so only a few attempts are needed.
+func randomBinaryMatrix(rng *rand.Rand, n int) (binaryMatrix, binaryMatrix) {
+	for {
+		m := make(binaryMatrix, n)
+		for i := range m {
+			m[i] = rng.Uint32() >> (32 - n)
+		}
+
+		if inv, ok := m.inverse(); ok {
+			return m, inv
+		}
+	}
+}
+
+// inverse uses Gauss-Jordan elimination, applying every row operation to
+// the identity matrix as well. It reports false if the matrix is singular.
+func (m binaryMatrix) inverse() (binaryMatrix, bool) {
+	n := len(m)
+	a := append(binaryMatrix{}, m...)
+	inv := make(binaryMatrix, n)
+	for i := range inv {
+		inv[i] = 1 << (n - 1 - i)
+	}
+
+	for col := 0; col < n; col++ {
+		bit := uint32(1) << (n - 1 - col)
+
+		pivot := -1
+		for row := col; row < n; row++ {
+			if a[row]&bit != 0 {
+				pivot = row
+				break
+			}
+		}
+		if pivot < 0 {
+			return nil, false
+		}
+
+		a[col], a[pivot] = a[pivot], a[col]
+		inv[col], inv[pivot] = inv[pivot], inv[col]
+
+		for row := 0; row < n; row++ {
+			if row != col && a[row]&bit != 0 {
+				a[row] ^= a[col]
+				inv[row] ^= inv[col]
+			}
+		}
+	}
+
+	return inv, true
+}
+
+// nibbleEncoding is a random bijection on 4-bit values,
+// used to hide the values that flow between tables.
+type nibbleEncoding struct {
+	encode, decode [16]byte
+}
+
+func randomNibbleEncoding(rng *rand.Rand) nibbleEncoding {
+	var e nibbleEncoding
+	for i, v := range rng.Perm(16) {
+		e.encode[i] = byte(v)
+		e.decode[v] = byte(i)
+	}
+
+	return e
+}
+
+// identityNibbleEncoding leaves values as they are.
+func identityNibbleEncoding() nibbleEncoding {
+	var e nibbleEncoding
+	for i := range e.encode {
+		e.encode[i] = byte(i)
+		e.decode[i] = byte(i)
+	}
+
+	return e
+}
+
+// wordEncoding encodes each of the 8 nibbles of a 32-bit word separately,
+// with nibble 0 being the most significant one.
+type wordEncoding [8]nibbleEncoding
+
+func randomWordEncoding(rng *rand.Rand) wordEncoding {
+	var e wordEncoding
+	for i := range e {
+		e[i] = randomNibbleEncoding(rng)
+	}
+
+	return e
+}
+
+func (e wordEncoding) encode(w uint32) uint32 {
+	var out uint32
+	for i := range e {
+		out = out<<4 | uint32(e[i].encode[w>>(28-4*i)&0xf])
+	}
+
+	return out
+}
+
+// byteEncoding encodes the high and low nibble of a byte separately.
+type byteEncoding [2]nibbleEncoding
+
+func (e byteEncoding) decode(b byte) byte {
+	return e[0].decode[b>>4]<<4 | e[1].decode[b&0xf]
+}
+
+// byteEncoding returns the encoding of byte i of a word, with byte 0 being
+// the most significant one.
+func (e wordEncoding) byteEncoding(i int) byteEncoding {
+	return byteEncoding{e[2*i], e[2*i+1]}
+}
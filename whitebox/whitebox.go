@@ -0,0 +1,262 @@
+// Package whitebox generates white-box lookup tables for AES-128, following
+// S. Chow, P. Eisen, H. Johnson and P. C. van Oorschot, 'White-Box
+// Cryptography and an AES Implementation', SAC 2002.
+//
+// The round keys are merged into the S-box lookups, and every table is
+// wrapped in random linear mixing bijections and nonlinear nibble encodings,
+// so that Tables can encrypt without the key being present in memory.
+// Chow's construction is known to be broken by the BGE attack, which
+// recovers the key from the tables in about 2³⁰ steps; it is meant for research
+// and teaching, not for protecting real keys. External encodings are not used,
+// so the tables take and return plain blocks.
+// See also J. Muir, 'A Tutorial on White-box AES', 2013.
+package whitebox
+
+import (
+	"encoding/binary"
+	"io"
+	"math/rand"
+
+	"github.com/intersesh/crypto/aes"
+	"github.com/intersesh/crypto/blockcipher"
+)
+
+const numRounds = 10
+
+// xorTables add four encoded 32-bit words, one nibble at a time.
+// Level 0 adds the first two words, level 1 the last two, and level 2 the two sums.
+// Each table maps an encoded nibble of both inputs, with the first input in the
+// high half of the index, to their encoded sum.
+type xorTables [3][8][256]byte
+
+// Tables holds everything needed to encrypt with one AES-128 key.
+//
+// For each of the first nine rounds and each byte of the state after ShiftRows,
+// a TypeII table combines AddRoundKey, SubBytes and that byte's share of
+// MixColumns (a T-box followed by a Tyi table), and applies a 32-bit mixing
+// bijection per column. TypeIII tables undo that bijection and apply the 8-bit
+// mixing bijections the next round expects. Their outputs are added with the
+// XOR tables. The last round only needs one 8-bit table per byte.
+type Tables struct {
+	TypeII    [numRounds - 1][16][256]uint32
+	XorII     [numRounds - 1][4]xorTables
+	TypeIII   [numRounds - 1][16][256]uint32
+	XorIII    [numRounds - 1][4]xorTables
+	LastRound [16][256]byte
+}
+
+// shifted returns the index of the state byte that ShiftRows moves to index i.
+// See FIPS-197 Section 5.1.2.
+func shifted(i int) int {
+	column, row := i/4, i%4
+	return 4*((column+row)%4) + row
+}
+
+// unshifted is the inverse of shifted.
+func unshifted(i int) int {
+	column, row := i/4, i%4
+	return 4*((column-row+4)%4) + row
+}
+
+// mixColumnCoefficients is the MixColumns matrix from FIPS-197 Section 5.1.3.
+var mixColumnCoefficients = aes.MixColumnsMatrix()
+
+// sbox is the AES S-box, see aes.SBoxTable.
+var sbox = aes.SBoxTable()
+
+// tyi returns the contribution of a byte in the given row to its column after MixColumns.
+func tyi(row int, b byte) uint32 {
+	var out uint32
+	for r := 0; r < 4; r++ {
+		out = out<<8 | uint32(aes.Multiply(mixColumnCoefficients[r][row], b))
+	}
+
+	return out
+}
+
+// Generate returns white-box tables for key, with mixing bijections and
+// encodings drawn from a generator with the given seed.
+func Generate(key [16]byte, seed int64) *Tables {
+	rng := rand.New(rand.NewSource(seed))
+	schedule := aes.NewAES128(key).KeySchedule()
+
+	var roundKeys [numRounds + 1][16]byte
+	for r := range roundKeys {
+		for i, w := range schedule.RoundKey(r) {
+			binary.BigEndian.PutUint32(roundKeys[r][4*i:], uint32(w))
+		}
+	}
+
+	// inputMixing[r][i] is the 8-bit mixing bijection on byte i of the state
+	// after ShiftRows in round r+1. The first round takes the plaintext as is.
+	var inputMixing, inputMixingInverse [numRounds][16]binaryMatrix
+	for r := range inputMixing {
+		for i := range inputMixing[r] {
+			if r == 0 {
+				inputMixing[r][i] = binaryMatrix{0x80, 0x40, 0x20, 0x10, 0x08, 0x04, 0x02, 0x01}
+				inputMixingInverse[r][i] = inputMixing[r][i]
+				continue
+			}
+
+			inputMixing[r][i], inputMixingInverse[r][i] = randomBinaryMatrix(rng, 8)
+		}
+	}
+
+	// stateEncoding[i] is the encoding of byte i of the state entering the current round.
+	var stateEncoding [16]byteEncoding
+	for i := range stateEncoding {
+		stateEncoding[i] = byteEncoding{identityNibbleEncoding(), identityNibbleEncoding()}
+	}
+
+	t := &Tables{}
+	for r := 0; r < numRounds-1; r++ {
+		var next [16]byteEncoding
+
+		for column := 0; column < 4; column++ {
+			mixing, mixingInverse := randomBinaryMatrix(rng, 32)
+
+			var typeIIOut [4]wordEncoding
+			for row := 0; row < 4; row++ {
+				i := 4*column + row
+				in := stateEncoding[shifted(i)]
+				typeIIOut[row] = randomWordEncoding(rng)
+
+				for x := 0; x < 256; x++ {
+					a := byte(inputMixingInverse[r][i].apply(uint32(in.decode(byte(x)))))
+					y := tyi(row, sbox[a^roundKeys[r][shifted(i)]])
+					t.TypeII[r][i][x] = typeIIOut[row].encode(mixing.apply(y))
+				}
+			}
+
+			mixed := generateXorTables(rng, &t.XorII[r][column], typeIIOut)
+
+			var typeIIIOut [4]wordEncoding
+			for j := 0; j < 4; j++ {
+				i := 4*column + j
+				in := mixed.byteEncoding(j)
+				typeIIIOut[j] = randomWordEncoding(rng)
+
+				for x := 0; x < 256; x++ {
+					y := mixingInverse.apply(uint32(in.decode(byte(x))) << (24 - 8*j))
+
+					// Byte k of the column is byte unshifted(4*column+k)
+					// of the next round's state after ShiftRows.
+					var z uint32
+					for k := 0; k < 4; k++ {
+						b := byte(y >> (24 - 8*k))
+						z = z<<8 | inputMixing[r+1][unshifted(4*column+k)].apply(uint32(b))
+					}
+
+					t.TypeIII[r][i][x] = typeIIIOut[j].encode(z)
+				}
+			}
+
+			out := generateXorTables(rng, &t.XorIII[r][column], typeIIIOut)
+			for k := 0; k < 4; k++ {
+				next[4*column+k] = out.byteEncoding(k)
+			}
+		}
+
+		stateEncoding = next
+	}
+
+	last := numRounds - 1
+	for i := 0; i < 16; i++ {
+		in := stateEncoding[shifted(i)]
+		for x := 0; x < 256; x++ {
+			a := byte(inputMixingInverse[last][i].apply(uint32(in.decode(byte(x)))))
+			t.LastRound[i][x] = sbox[a^roundKeys[last][shifted(i)]] ^ roundKeys[numRounds][i]
+		}
+	}
+
+	return t
+}
+
+// generateXorTables fills in the tables that add four words with the given
+// encodings, and returns the encoding of the sum.
+func generateXorTables(rng *rand.Rand, tables *xorTables, in [4]wordEncoding) wordEncoding {
+	sums := [3]wordEncoding{randomWordEncoding(rng), randomWordEncoding(rng), randomWordEncoding(rng)}
+	inputs := [3][2]wordEncoding{
+		{in[0], in[1]},
+		{in[2], in[3]},
+		{sums[0], sums[1]},
+	}
+
+	for level := range tables {
+		for n := 0; n < 8; n++ {
+			a, b := inputs[level][0][n], inputs[level][1][n]
+			for x := 0; x < 256; x++ {
+				tables[level][n][x] = sums[level][n].encode[a.decode[x>>4]^b.decode[x&0xf]]
+			}
+		}
+	}
+
+	return sums[2]
+}
+
+// Encrypt encrypts a block using nothing but table lookups.
+func (t *Tables) Encrypt(block blockcipher.Block) blockcipher.Block {
+	state := block
+
+	for r := 0; r < numRounds-1; r++ {
+		var next blockcipher.Block
+
+		for column := 0; column < 4; column++ {
+			var words [4]uint32
+			for row := 0; row < 4; row++ {
+				i := 4*column + row
+				words[row] = t.TypeII[r][i][state[shifted(i)]]
+			}
+
+			mixed := t.XorII[r][column].add(words)
+			for j := 0; j < 4; j++ {
+				words[j] = t.TypeIII[r][4*column+j][byte(mixed>>(24-8*j))]
+			}
+
+			binary.BigEndian.PutUint32(next[4*column:], t.XorIII[r][column].add(words))
+		}
+
+		state = next
+	}
+
+	var out blockcipher.Block
+	for i := range out {
+		out[i] = t.LastRound[i][state[shifted(i)]]
+	}
+
+	return out
+}
+
+func (x *xorTables) add(words [4]uint32) uint32 {
+	level := func(tables *[8][256]byte, a, b uint32) uint32 {
+		var out uint32
+		for n := 0; n < 8; n++ {
+			shift := 28 - 4*n
+			out = out<<4 | uint32(tables[n][(a>>shift&0xf)<<4|b>>shift&0xf])
+		}
+
+		return out
+	}
+
+	return level(&x[2], level(&x[0], words[0], words[1]), level(&x[1], words[2], words[3]))
+}
+
+// WriteTo writes the tables to w in a fixed binary layout,
+// so that they can be shipped and evaluated without the key.
+func (t *Tables) WriteTo(w io.Writer) (int64, error) {
+	if err := binary.Write(w, binary.BigEndian, t); err != nil {
+		return 0, err
+	}
+
+	return int64(binary.Size(t)), nil
+}
+
+// ReadTables reads tables written by WriteTo.
+func ReadTables(r io.Reader) (*Tables, error) {
+	t := &Tables{}
+	if err := binary.Read(r, binary.BigEndian, t); err != nil {
+		return nil, err
+	}
+
+	return t, nil
+}
@@ -0,0 +1,68 @@
+package whitebox
+
+import (
+	"bytes"
+	"math/rand"
+	"testing"
+
+	"github.com/intersesh/crypto/aes"
+	"github.com/intersesh/crypto/blockcipher"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTables(t *testing.T) {
+	for i := 0; i < 4; i++ {
+		var key [16]byte
+		copy(key[:], blockcipher.RandomBytes(16))
+
+		c := aes.NewAES128(key)
+		tables := Generate(key, int64(i))
+
+		for j := 0; j < 16; j++ {
+			block := blockcipher.NewBlock(blockcipher.RandomBytes(16))
+			assert.Equal(t, c.Encrypt(block), tables.Encrypt(block))
+		}
+	}
+}
+
+// TestTablesFIPS197 uses the example from FIPS-197 Appendix B.
+func TestTablesFIPS197(t *testing.T) {
+	key := [16]byte{0x2b, 0x7e, 0x15, 0x16, 0x28, 0xae, 0xd2, 0xa6, 0xab, 0xf7, 0x15, 0x88, 0x09, 0xcf, 0x4f, 0x3c}
+	block := blockcipher.Block{0x32, 0x43, 0xf6, 0xa8, 0x88, 0x5a, 0x30, 0x8d, 0x31, 0x31, 0x98, 0xa2, 0xe0, 0x37, 0x07, 0x34}
+
+	tables := Generate(key, 1)
+	assert.Equal(t, blockcipher.Block{0x39, 0x25, 0x84, 0x1d, 0x2, 0xdc, 0x9, 0xfb, 0xdc, 0x11, 0x85, 0x97, 0x19, 0x6a, 0xb, 0x32}, tables.Encrypt(block))
+}
+
+func TestWriteTo(t *testing.T) {
+	var key [16]byte
+	copy(key[:], blockcipher.RandomBytes(16))
+	tables := Generate(key, 1)
+
+	var buf bytes.Buffer
+	n, err := tables.WriteTo(&buf)
+	require.NoError(t, err)
+	assert.Equal(t, int64(buf.Len()), n)
+
+	// The key must not appear in the tables as is.
+	assert.False(t, bytes.Contains(buf.Bytes(), key[:]))
+
+	read, err := ReadTables(&buf)
+	require.NoError(t, err)
+
+	block := blockcipher.NewBlock(blockcipher.RandomBytes(16))
+	assert.Equal(t, aes.NewAES128(key).Encrypt(block), read.Encrypt(block))
+}
+
+func TestBinaryMatrixInverse(t *testing.T) {
+	m, inv := randomBinaryMatrix(rngForTest(), 32)
+	for i := 0; i < 100; i++ {
+		x := uint32(i * 0x9e3779b9)
+		assert.Equal(t, x, inv.apply(m.apply(x)))
+	}
+}
+
+func rngForTest() *rand.Rand {
+	return rand.New(rand.NewSource(1))
+}